@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/deployer"
@@ -15,7 +16,8 @@ import (
 const pluginPrereleaseDesc = `Draft a pre-release to SoftLeader docker swarm ecosystem
 
 建立 pre-release 版本, pre 為此 command 的縮寫, 傳入 '--interactive' 可以開啟互動模式
-在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加一個 patch 版號做為問答預設的 tag:
+在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加一個版號做為問答預設的 tag
+預設增加的是 patch 版號, 你可以透過 '--bump' 調整為 'minor', 'major' 或依照 Conventional Commits 自動判斷的 'auto':
 
 	$ s2i prerelease TAG
 	$ s2i pre -i
@@ -61,6 +63,14 @@ type prereleaseCmd struct {
 	Force           bool
 	interactive     bool
 	promptSize      int
+	bump            string
+	fromTags        bool
+	stableOnly      bool
+	channel         string
+	vPrefix         bool
+	requireNewer    bool
+	checkOrder      bool
+	tagMessage      string
 	SourceOwner     string `yaml:"source-owner"`
 	SourceRepo      string `yaml:"source-repo"`
 	SourceBranch    string `yaml:"source-branch"`
@@ -104,13 +114,15 @@ func newPrereleaseCmd() *cobra.Command {
 					token = t
 				}
 				c.Image.Name = c.SourceRepo
-				c.SourceBranch = github.Head(logrus.StandardLogger(), c.pwd)
+				if !cmd.Flags().Changed("source-branch") { // 沒有指定 branch 或 commit sha 時才從當前目錄找, 找不到再使用 default branch
+					c.SourceBranch = github.ResolveBranch(context.Background(), logrus.StandardLogger(), github.HeadOf(c.pwd), github.DefaultBranchOf(token, c.SourceOwner, c.SourceRepo))
+				}
 				c.Auth = jib.GetAuth(logrus.StandardLogger(), c.pwd)
 			}
 			if c.interactive {
 				if c.Image.Tag == "" {
 					var err error
-					c.Image.Tag, err = github.FindNextReleaseVersion(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo)
+					c.Image.Tag, err = github.FindNextReleaseVersion(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, &github.NextVersionOptions{Bump: github.Bump(c.bump), FromTags: c.fromTags, StableOnly: c.stableOnly, Channel: c.channel, VPrefix: c.vPrefix, EnforceVPrefix: cmd.Flags().Changed("v-prefix")})
 					if err != nil {
 						logrus.Debugln(err)
 					}
@@ -127,15 +139,23 @@ func newPrereleaseCmd() *cobra.Command {
 	}
 
 	f := cmd.Flags()
-	f.BoolVarP(&c.Force, "force", "f", false, "force to delete the tag if it already exists")
+	f.BoolVarP(&c.Force, "force", "f", false, "force to delete the release and tag if they already exist on the same branch")
 	f.BoolVarP(&c.interactive, "interactive", "i", false, "interactive prompt")
 	f.IntVar(&c.promptSize, "interactive-prompt-size", 7, "interactive prompt size")
+	f.StringVar(&c.bump, "bump", string(github.BumpPatch), "bump level of the next version in interactive mode, e.g. patch, minor, major, auto, prerelease or finalize")
+	f.BoolVar(&c.fromTags, "from-tags", false, "base the next version on the highest semver tag instead of the latest release in interactive mode")
+	f.BoolVar(&c.stableOnly, "stable-only", false, "base the next version on stable versions only, ignoring any pre-release in interactive mode")
+	f.StringVar(&c.channel, "channel", "", "compute the next version within the channel in interactive mode, e.g. 'beta' bumps v1.2.0-beta.3 to v1.2.0-beta.4, 'stable' is the same as --stable-only")
+	f.BoolVar(&c.vPrefix, "v-prefix", false, "whether to prefix the next version with \"v\" in interactive mode, defaults to follow the latest release")
+	f.BoolVar(&c.requireNewer, "require-newer", false, "refuse to create the release if the tag is not newer than the latest release")
+	f.BoolVar(&c.checkOrder, "check-order", false, "warn if the pre-release is lower than an existing pre-release of the same version, refused when --require-newer")
+	f.StringVar(&c.tagMessage, "tag-message", "", "create an annotated tag with the message instead of a lightweight tag")
 	f.BoolVar(&c.SkipTests, "skip-tests", false, "skip tests when building image")
 	f.BoolVar(&c.SkipDraft, "skip-draft", false, "skip draft pre-release tag")
 	f.BoolVarP(&c.UpdateSnapshots, "update-snapshots", "U", false, "force to check for updated snapshots on remote repositories")
 	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to create tag")
 	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to create tag")
-	f.StringVar(&c.SourceBranch, "source-branch", c.SourceBranch, "name of branch to create tag")
+	f.StringVar(&c.SourceBranch, "source-branch", c.SourceBranch, "name of branch or commit sha to create tag, defaults to HEAD of current directory")
 	f.StringVar(&c.ConfigServer, "config-server", "http://softleader.com.tw:8887", "config server to run the test")
 	f.StringVar(&c.ConfigLabel, "config-label", "", "the label of config server to run the test, e.g. sqlServer")
 	f.StringVar(&c.Image.Name, "image", c.Image.Name, "name of image to build")
@@ -161,7 +181,7 @@ func (c *prereleaseCmd) run() (err error) {
 		return err
 	}
 	if !c.SkipDraft {
-		if _, err = github.CreatePrerelease(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.Image.Tag, c.Force); err != nil {
+		if _, err = github.CreatePrerelease(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.Image.Tag, c.Force, &github.ReleaseOptions{RequireNewer: c.requireNewer, CheckPrereleaseOrder: c.checkOrder, TagMessage: c.tagMessage}); err != nil {
 			return err
 		}
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/docker"
@@ -16,7 +17,8 @@ import (
 const pluginReleaseDesc = `Draft a release to SoftLeader docker swarm ecosystem
 
 建立 release 版本, 傳入 '--interactive' 可以開啟互動模式
-在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加一個 patch 版號做為問答預設的 tag:
+在互動模式下, tag 若不傳入就會自動的到 GitHub 找出 latest release 並增加一個版號做為問答預設的 tag
+預設增加的是 patch 版號, 你可以透過 '--bump' 調整為 'minor', 'major' 或依照 Conventional Commits 自動判斷的 'auto':
 
 	$ s2i release TAG
 	$ s2i release -i
@@ -43,6 +45,21 @@ var (
 type releaseCmd struct {
 	interactive     bool
 	promptSize      int
+	bump            string
+	fromTags        bool
+	stableOnly      bool
+	channel         string
+	vPrefix         bool
+	requireNewer    bool
+	tagMessage      string
+	idempotent      bool
+	existingTag     bool
+	generateNotes   bool
+	requireSigned   bool
+	makeLatest      string
+	commentOnCommit bool
+	discussion      string
+	verifyBranch    bool
 	SourceOwner     string `yaml:"source-owner"`
 	SourceRepo      string `yaml:"source-repo"`
 	SourceBranch    string `yaml:"source-branch"`
@@ -77,12 +94,14 @@ func newReleaseCmd() *cobra.Command {
 					token = t // 代表此 repo 是用指定 token clone 的, 因此換掉這次 global 的 token
 				}
 				c.Image.Name = c.SourceRepo
-				c.SourceBranch = github.Head(logrus.StandardLogger(), pwd)
+				if !cmd.Flags().Changed("source-branch") { // 沒有指定 branch 或 commit sha 時才從當前目錄找, 找不到再使用 default branch
+					c.SourceBranch = github.ResolveBranch(context.Background(), logrus.StandardLogger(), github.HeadOf(pwd), github.DefaultBranchOf(token, c.SourceOwner, c.SourceRepo))
+				}
 			}
 			if c.interactive {
 				if c.Image.Tag == "" {
 					var err error
-					c.Image.Tag, err = github.FindNextReleaseVersion(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo)
+					c.Image.Tag, err = github.FindNextReleaseVersion(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, &github.NextVersionOptions{Bump: github.Bump(c.bump), FromTags: c.fromTags, StableOnly: c.stableOnly, Channel: c.channel, VPrefix: c.vPrefix, EnforceVPrefix: cmd.Flags().Changed("v-prefix")})
 					if err != nil {
 						logrus.Debugln(err)
 					}
@@ -101,9 +120,24 @@ func newReleaseCmd() *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVarP(&c.interactive, "interactive", "i", false, "interactive prompt")
 	f.IntVar(&c.promptSize, "interactive-prompt-size", 7, "interactive prompt size")
+	f.StringVar(&c.bump, "bump", string(github.BumpPatch), "bump level of the next version in interactive mode, e.g. patch, minor, major, auto, prerelease or finalize")
+	f.BoolVar(&c.fromTags, "from-tags", false, "base the next version on the highest semver tag instead of the latest release in interactive mode")
+	f.BoolVar(&c.stableOnly, "stable-only", false, "base the next version on stable versions only, ignoring any pre-release in interactive mode")
+	f.StringVar(&c.channel, "channel", "", "compute the next version within the channel in interactive mode, e.g. 'beta' bumps v1.2.0-beta.3 to v1.2.0-beta.4, 'stable' is the same as --stable-only")
+	f.BoolVar(&c.vPrefix, "v-prefix", false, "whether to prefix the next version with \"v\" in interactive mode, defaults to follow the latest release")
+	f.BoolVar(&c.requireNewer, "require-newer", false, "refuse to create the release if the tag is not newer than the latest release")
+	f.StringVar(&c.tagMessage, "tag-message", "", "create an annotated tag with the message instead of a lightweight tag")
+	f.BoolVar(&c.requireSigned, "require-signed-commit", false, "refuse to create the release if the commit is not signed and verified by GitHub")
+	f.BoolVar(&c.generateNotes, "generate-release-notes", false, "let GitHub generate the release notes from pull requests merged since the previous tag")
+	f.BoolVar(&c.existingTag, "existing-tag", false, "bind the release to the tag if it already exists instead of creating it from source branch")
+	f.StringVar(&c.makeLatest, "make-latest", "", "whether to mark the release as latest: true, false or legacy, defaults to let GitHub decide")
+	f.BoolVar(&c.commentOnCommit, "comment-on-commit", false, "leave a comment linking to the release on the released commit")
+	f.StringVar(&c.discussion, "discussion-category", "", "create a discussion linked to the release in the category, discussions must be enabled in the repo")
+	f.BoolVar(&c.verifyBranch, "verify-branch", false, "make sure the source branch exists before creating the release")
+	f.BoolVar(&c.idempotent, "idempotent", false, "skip creating if the release of tag already exists on the same branch, safe to re-run")
 	f.StringVar(&c.SourceOwner, "source-owner", c.SourceOwner, "name of the owner (user or org) of the repo to create tag")
 	f.StringVar(&c.SourceRepo, "source-repo", c.SourceRepo, "name of repo to create tag")
-	f.StringVar(&c.SourceBranch, "source-branch", c.SourceBranch, "name of branch to create tag")
+	f.StringVar(&c.SourceBranch, "source-branch", c.SourceBranch, "name of branch or commit sha to create tag, defaults to HEAD of current directory")
 	f.StringVar(&c.Image.Name, "image", c.Image.Name, "name of image to build")
 	f.StringVar(&c.Jenkins, "jenkins", "https://jenkins.softleader.com.tw", "jenkins to run the pipeline")
 	f.StringVar(&c.Deployer, "deployer", "http://softleader.com.tw:5678", "deployer to deploy")
@@ -113,7 +147,7 @@ func newReleaseCmd() *cobra.Command {
 }
 
 func (c *releaseCmd) run() (err error) {
-	if _, err := github.CreateRelease(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.Image.Tag); err != nil {
+	if _, err := github.CreateRelease(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.SourceBranch, c.Image.Tag, &github.ReleaseOptions{RequireNewer: c.requireNewer, TagMessage: c.tagMessage, Idempotent: c.idempotent, ExistingTag: c.existingTag, GenerateReleaseNotes: c.generateNotes, RequireSignedCommit: c.requireSigned, MakeLatest: c.makeLatest, CommentOnCommit: c.commentOnCommit, DiscussionCategory: c.discussion, VerifyBranch: c.verifyBranch}); err != nil {
 		return err
 	}
 
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/formatter"
+	"github.com/softleader/s2i/pkg/github"
 	"github.com/softleader/s2i/pkg/release"
 	"github.com/spf13/cobra"
 	"os"
 	"strconv"
+	"time"
 )
 
 var (
@@ -19,6 +21,9 @@ var (
 	offline, _ = strconv.ParseBool(os.Getenv("SL_OFFLINE"))
 	verbose, _ = strconv.ParseBool(os.Getenv("SL_VERBOSE"))
 	token      = os.Getenv("SL_TOKEN")
+	githubOpts = &github.ClientOptions{}
+	gitCLI     bool
+	quiet      bool
 )
 
 func main() {
@@ -26,7 +31,29 @@ func main() {
 		initMetadata,
 	)
 	if err := newRootCmd(os.Args[1:]).Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode 依照錯誤的種類決定 exit code, 並提示使用者如何處理
+func exitCode(err error) int {
+	switch github.KindOf(err) {
+	case github.KindInvalid:
+		return 2
+	case github.KindUnauthorized:
+		logrus.Errorln("please check your github access token, you can pass it by '--token' or $SL_TOKEN")
+		return 3
+	case github.KindNotFound:
+		logrus.Errorln("please check the owner, repo and tag, or make sure your token has access to the repo")
+		return 4
+	case github.KindRateLimited:
+		logrus.Errorln("GitHub rate limit exceeded, please try again later or pass '--github-max-retries' to wait for it")
+		return 5
+	case github.KindTimeout:
+		logrus.Errorln("GitHub did not respond in time, please check your network or try again later")
+		return 6
+	default:
+		return 1
 	}
 }
 
@@ -45,6 +72,12 @@ func newRootCmd(args []string) *cobra.Command {
 			if verbose {
 				logrus.SetLevel(logrus.DebugLevel)
 			}
+			if githubOpts.UserAgent == "" {
+				githubOpts.UserAgent = fmt.Sprintf("s2i/%s", metadata)
+			}
+			github.SetClientOptions(githubOpts)
+			github.SetGitCLI(gitCLI)
+			github.SetQuiet(quiet)
 			return nil
 		},
 	}
@@ -61,7 +94,24 @@ func newRootCmd(args []string) *cobra.Command {
 	f := cmd.PersistentFlags()
 	f.BoolVar(&offline, "offline", offline, "work offline, Overrides $SL_OFFLINE")
 	f.BoolVarP(&verbose, "verbose", "v", verbose, "enable verbose output, Overrides $SL_VERBOSE")
+	f.BoolVarP(&quiet, "quiet", "q", false, "suppress success messages of GitHub, for scripts to capture only the data they need")
 	f.StringVar(&token, "token", token, "github access token. Overrides $SL_TOKEN")
+	f.StringVar(&githubOpts.TokenFile, "token-file", "", "path of file containing the github access token, used when '--token' is not passed")
+	f.StringVar(&githubOpts.BaseURL, "github-base-url", "", "base url of GitHub Enterprise Server API, e.g. https://github.example.com/api/v3/")
+	f.StringVar(&githubOpts.UploadURL, "github-upload-url", "", "upload url of release assets, defaults to /api/uploads/ on the host of --github-base-url or github.com if not specified")
+	f.IntVar(&githubOpts.Retry.MaxRetries, "github-max-retries", 0, "max retries when GitHub rate limit exceeded, 0 for no retry")
+	f.DurationVar(&githubOpts.Retry.BaseDelay, "github-retry-base-delay", time.Minute, "delay before the first retry when GitHub does not tell when to retry, doubled on each retry")
+	f.IntVar(&githubOpts.Retry.MaxTransientRetries, "github-max-transient-retries", 0, "max retries on GitHub 5xx or transient connection errors, 0 for no retry")
+	f.DurationVar(&githubOpts.Retry.TransientDelay, "github-transient-retry-delay", time.Second, "delay before the first retry on transient errors, doubled on each retry")
+	f.DurationVar(&githubOpts.Retry.MaxDelay, "github-retry-max-delay", 0, "max delay of each retry, 0 for no limit")
+	f.BoolVar(&githubOpts.Retry.Jitter, "github-retry-jitter", true, "add random jitter up to base delay to each retry, to avoid jobs retrying at the same time")
+	f.StringVar(&githubOpts.UserAgent, "github-user-agent", "", "User-Agent to identify the requests to GitHub, defaults to s2i/<version>")
+	f.StringVar(&githubOpts.APIVersion, "github-api-version", "", "X-GitHub-Api-Version header to pin the GitHub REST API version, defaults to 2022-11-28")
+	f.IntVar(&githubOpts.RateLimitWarning, "github-rate-limit-warning", 0, "warn when the remaining GitHub rate limit drops below the threshold after creating release, 0 for no warning")
+	f.DurationVar(&githubOpts.Timeout, "github-timeout", 30*time.Second, "timeout waiting for GitHub to respond to each request, counted per retry and excluding asset transfers")
+	f.StringVar(&githubOpts.Proxy, "github-proxy", "", "proxy url to connect to GitHub, defaults to $HTTPS_PROXY or $HTTP_PROXY")
+	f.BoolVar(&githubOpts.InsecureSkipVerify, "github-insecure-skip-tls-verify", false, "INSECURE: skip TLS certificate verification of GitHub, for self-signed GitHub Enterprise Server in testing only")
+	f.BoolVar(&gitCLI, "git-cli", false, "always use git command to resolve the remote and HEAD of current directory, instead of parsing .git, requires git in PATH")
 	f.Parse(args)
 
 	return cmd
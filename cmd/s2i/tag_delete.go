@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/github"
@@ -114,14 +115,14 @@ func (c *tagDeleteCmd) run() error {
 		if err != nil {
 			return err
 		}
-		return github.DeleteMatchesReleasesAndTags(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher, c.DryRun)
+		return github.DeleteMatchesReleasesAndTags(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher, c.DryRun)
 	}
 	if c.SemVer {
 		matcher, err := github.NewSemVerMatcher(c.Tags)
 		if err != nil {
 			return err
 		}
-		return github.DeleteMatchesReleasesAndTags(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher, c.DryRun)
+		return github.DeleteMatchesReleasesAndTags(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher, c.DryRun)
 	}
-	return github.DeleteReleasesAndTags(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.Tags, c.DryRun)
+	return github.DeleteReleasesAndTags(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.Tags, c.DryRun)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/softleader/s2i/pkg/github"
@@ -90,14 +91,14 @@ func (c *tagListCmd) run() error {
 		if err != nil {
 			return err
 		}
-		return github.ListReleaseByMatcher(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher)
+		return github.ListReleaseByMatcher(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher)
 	}
 	if c.SemVer {
 		matcher, err := github.NewSemVerMatcher(c.Tags)
 		if err != nil {
 			return err
 		}
-		return github.ListReleaseByMatcher(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher)
+		return github.ListReleaseByMatcher(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, matcher)
 	}
-	return github.ListRelease(logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.Tags)
+	return github.ListRelease(context.Background(), logrus.StandardLogger(), token, c.SourceOwner, c.SourceRepo, c.Tags)
 }
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"strings"
+	"time"
+)
+
+const (
+	// appJWTExpiration GitHub 允許 App JWT 最長的有效時間為 10 分鐘
+	appJWTExpiration = 10 * time.Minute
+	// appJWTClockDrift 為避免跟 GitHub 的時間誤差, iat 往前調整的時間
+	appJWTClockDrift = time.Minute
+)
+
+// AppInstallationToken 以 GitHub App 的身份換取 installation access token
+// 回傳的 token 可以直接傳入 CreateRelease 等 function, 以 App 的權限跟 github 互動
+func AppInstallationToken(ctx context.Context, log logrus.FieldLogger, appID, installationID int64, privateKey []byte) (string, error) {
+	jwt, err := appJWT(appID, privateKey, now())
+	if err != nil {
+		return "", err
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: jwt, TokenType: "Bearer"},
+	)
+	client, err := newClient(ctx, log, ts)
+	if err != nil {
+		return "", err
+	}
+	log.Debugf("creating installation token of app %d for installation %d", appID, installationID)
+	it, _, err := client.Apps.CreateInstallationToken(ctx, installationID, nil)
+	if err != nil {
+		return "", err
+	}
+	log.Debugf("installation token expires at %s", it.GetExpiresAt())
+	return it.GetToken(), nil
+}
+
+// NewAppClient 建立以 GitHub App installation 身份跟 github 互動的 Client, privateKey 為 App 的 PEM 格式 private key
+// installation token 約一小時後失效, 需要長時間執行時應重新建立 Client
+func NewAppClient(ctx context.Context, log logrus.FieldLogger, appID, installationID int64, privateKey []byte) (*Client, error) {
+	token, err := AppInstallationToken(ctx, log, appID, installationID, privateKey)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return NewClient(ctx, log, token)
+}
+
+// appJWT 產生 GitHub App 認證用的 RS256 JWT
+func appJWT(appID int64, privateKey []byte, now time.Time) (string, error) {
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-appJWTClockDrift).Unix(),
+		"exp": now.Add(appJWTExpiration - appJWTClockDrift).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString(header),
+		base64.RawURLEncoding.EncodeToString(claims),
+	}, ".")
+	hashed := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey 解析 PEM 格式的 RSA private key, 支援 PKCS#1 及 PKCS#8
+func parseRSAPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("requires a PEM encoded private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("requires a valid RSA private key: %s", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("requires a RSA private key")
+	}
+	return rsaKey, nil
+}
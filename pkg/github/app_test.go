@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	now := time.Unix(1570000000, 0)
+
+	jwt, err := appJWT(12345, pemKey, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("jwt should have 3 parts, but got %d", len(parts))
+	}
+	b, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]int64
+	if err := json.Unmarshal(b, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims["iss"] != 12345 || claims["iat"] != now.Unix()-60 || claims["exp"] != now.Unix()+540 {
+		t.Errorf("unexpected claims: %v", claims)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hashed[:], signature); err != nil {
+		t.Errorf("signature should be verified, but got %s", err)
+	}
+
+	if _, err := appJWT(12345, []byte("not a pem"), now); err == nil {
+		t.Error("invalid private key should return an error")
+	}
+}
+
+func TestNewAppClient(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/app/installations/678/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"token":"installation-token","expires_at":"2019-10-02T08:00:00Z"}`)
+		case r.URL.Path == "/repos/softleader/s2i":
+			fmt.Fprint(w, `{"default_branch":"main"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	SetClientOptions(&ClientOptions{BaseURL: server.URL + "/"})
+	log := logrus.StandardLogger()
+
+	c, err := NewAppClient(context.Background(), log, 12345, 678, pemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := c.GetDefaultBranch(context.Background(), "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "main" {
+		t.Errorf("default branch should be main, but got %q", branch)
+	}
+	if len(auths) != 2 || !strings.HasPrefix(auths[0], "Bearer ey") || auths[1] != "Bearer installation-token" {
+		t.Errorf("should exchange installation token by app jwt and then use it, but got %v", auths)
+	}
+
+	if _, err := NewAppClient(context.Background(), log, 12345, 999, pemKey); KindOf(err) != KindNotFound {
+		t.Errorf("missing installation should be not found, but got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+// BranchSource 取得 branch 的來源, 取不到時回傳空字串
+type BranchSource func(ctx context.Context, log logrus.FieldLogger) (string, error)
+
+// ExplicitBranch 直接使用傳入的 branch 或 commit sha, 通常來自使用者的參數
+func ExplicitBranch(branch string) BranchSource {
+	return func(ctx context.Context, log logrus.FieldLogger) (string, error) {
+		return branch, nil
+	}
+}
+
+// DefaultBranchOf 使用 repo 在 GitHub 上的 default branch, owner 或 repo 為空時略過
+func DefaultBranchOf(token, owner, repo string) BranchSource {
+	return func(ctx context.Context, log logrus.FieldLogger) (string, error) {
+		if owner == "" || repo == "" {
+			return "", nil
+		}
+		return GetDefaultBranch(ctx, log, token, owner, repo)
+	}
+}
+
+// HeadOf 使用 pwd 目錄的 git HEAD, HEAD 為 detached 時會以 commit sha 做為 branch 並提出警告
+func HeadOf(pwd string) BranchSource {
+	return func(ctx context.Context, log logrus.FieldLogger) (string, error) {
+		head, detached := Head(log, pwd)
+		if detached {
+			log.Warnf("HEAD is detached at %s, it will be used as the target commit of the tag", head)
+		}
+		return head, nil
+	}
+}
+
+// ResolveBranch 依序從 sources 取得 branch, 回傳第一個不為空的 branch, 皆取不到時回傳空字串
+// 來源發生錯誤時只會記錄在 debug log 並繼續嘗試下一個來源
+func ResolveBranch(ctx context.Context, log logrus.FieldLogger, sources ...BranchSource) string {
+	for _, source := range sources {
+		branch, err := source(ctx, log)
+		if err != nil {
+			log.Debugln(err)
+			continue
+		}
+		if branch != "" {
+			return branch
+		}
+	}
+	return ""
+}
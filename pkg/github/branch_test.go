@@ -0,0 +1,22 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestResolveBranch(t *testing.T) {
+	log := logrus.StandardLogger()
+	failed := func(ctx context.Context, log logrus.FieldLogger) (string, error) {
+		return "", errors.New("oops")
+	}
+
+	if branch := ResolveBranch(context.Background(), log, ExplicitBranch(""), failed, ExplicitBranch("develop"), ExplicitBranch("master")); branch != "develop" {
+		t.Errorf("branch should be the first non-empty one develop, but got %q", branch)
+	}
+	if branch := ResolveBranch(context.Background(), log, DefaultBranchOf("", "", ""), failed); branch != "" {
+		t.Errorf("branch should be empty when no source resolved, but got %q", branch)
+	}
+}
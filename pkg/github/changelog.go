@@ -0,0 +1,159 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+const (
+	shortSHA = 7
+)
+
+var (
+	// changelogHeader CHANGELOG.md 中每個版本的標題, 如: ## [1.2.3] - 2019-10-01
+	changelogHeader = regexp.MustCompile(`^##\s+\[([^\]]+)\]`)
+	// changelogLink CHANGELOG.md 最後的版本連結, 如: [1.2.3]: https://github.com/...
+	changelogLink = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+	// changelogSections 為 changelog 中各 Conventional Commits type 的標題及排列順序
+	changelogSections = []struct {
+		kind, title string
+	}{
+		{"feat", "Features"},
+		{"fix", "Bug Fixes"},
+		{"perf", "Performance Improvements"},
+		{"refactor", "Code Refactoring"},
+		{"docs", "Documentation"},
+		{"", "Others"},
+	}
+)
+
+// GenerateChangelog 產生 base...head 之間 commits 的 markdown changelog, 可做為 release 的 body
+// commits 會依照 Conventional Commits 的 type 分類, 不符合規範的 commit 會放在 Others 中
+func GenerateChangelog(ctx context.Context, log logrus.FieldLogger, token, owner, repo, base, head string) (string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.GenerateChangelog(ctx, owner, repo, base, head)
+}
+
+// GenerateChangelog 同 package function GenerateChangelog
+func (c *Client) GenerateChangelog(ctx context.Context, owner, repo, base, head string) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	commits, err := compareCommits(ctx, c.log, c.repos, owner, repo, base, head)
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return formatChangelog(commits), nil
+}
+
+// formatChangelog 將 commits 依照 Conventional Commits 的 type 分類成 markdown list
+func formatChangelog(commits []*github.RepositoryCommit) string {
+	groups := make(map[string][]string)
+	for _, c := range commits {
+		subject := strings.SplitN(c.GetCommit().GetMessage(), "\n", 2)[0]
+		kind := ""
+		if matches := conventional.FindStringSubmatch(subject); len(matches) > 0 {
+			kind = matches[1]
+			subject = strings.TrimPrefix(subject, matches[0])
+			if scope := strings.Trim(matches[2], "()"); scope != "" {
+				subject = fmt.Sprintf("**%s:** %s", scope, subject)
+			}
+		}
+		if !isChangelogSection(kind) {
+			kind = ""
+		}
+		sha := c.GetSHA()
+		if len(sha) > shortSHA {
+			sha = sha[:shortSHA]
+		}
+		groups[kind] = append(groups[kind], fmt.Sprintf("- %s (%s)", subject, sha))
+	}
+	var buf bytes.Buffer
+	for _, section := range changelogSections {
+		items := groups[section.kind]
+		if len(items) == 0 {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "### %s\n\n%s\n", section.title, strings.Join(items, "\n"))
+	}
+	return buf.String()
+}
+
+func isChangelogSection(kind string) bool {
+	for _, section := range changelogSections {
+		if section.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateReleaseFromChangelog 以本地 CHANGELOG.md 中 tag 版本的段落做為 body 建立 release
+// opts 中的 Body 會被 changelog 的內容取代, 找不到該版本的段落時回傳錯誤
+func CreateReleaseFromChangelog(ctx context.Context, log logrus.FieldLogger, token, owner, repo, branch, tag, path string, opts *ReleaseOptions) (*Release, error) {
+	c, err := clientFor(ctx, log, token, opts.dryRun())
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateReleaseFromChangelog(ctx, owner, repo, branch, tag, path, opts)
+}
+
+// CreateReleaseFromChangelog 同 package function CreateReleaseFromChangelog
+func (c *Client) CreateReleaseFromChangelog(ctx context.Context, owner, repo, branch, tag, path string, opts *ReleaseOptions) (*Release, error) {
+	body, err := ChangelogSection(path, tag)
+	if err != nil {
+		return nil, err
+	}
+	o := ReleaseOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Body = body
+	return c.CreateRelease(ctx, owner, repo, branch, tag, &o)
+}
+
+// ChangelogSection 讀取 CHANGELOG.md 並取出 version 的段落 (不含標題), version 是否有 v 開頭皆可
+func ChangelogSection(path, version string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	section, found := changelogSection(string(b), version)
+	if !found {
+		return "", &Error{Kind: KindNotFound, Err: fmt.Errorf("section of version %s not found in %s", version, path)}
+	}
+	return section, nil
+}
+
+// changelogSection 取出 version 標題到下一個版本標題之間的內容
+func changelogSection(changelog, version string) (string, bool) {
+	version = strings.TrimPrefix(version, "v")
+	var lines []string
+	found := false
+	for _, line := range strings.Split(changelog, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if matches := changelogHeader.FindStringSubmatch(line); len(matches) > 0 {
+			if found {
+				break
+			}
+			found = strings.TrimPrefix(matches[1], "v") == version
+			continue
+		}
+		if found && !changelogLink.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), found
+}
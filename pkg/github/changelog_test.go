@@ -0,0 +1,60 @@
+package github
+
+import (
+	"github.com/google/go-github/v28/github"
+	"testing"
+)
+
+func commit(sha, message string) *github.RepositoryCommit {
+	return &github.RepositoryCommit{
+		SHA:    github.String(sha),
+		Commit: &github.Commit{Message: github.String(message)},
+	}
+}
+
+func TestFormatChangelog(t *testing.T) {
+	commits := []*github.RepositoryCommit{
+		commit("1111111111", "feat(cmd): add bump flag"),
+		commit("2222222222", "fix: typo\n\nsome details"),
+		commit("3333333333", "update readme"),
+		commit("4444444444", "chore: release"),
+		commit("5555555555", "feat: add changelog"),
+	}
+	expected := `### Features
+
+- **cmd:** add bump flag (1111111)
+- add changelog (5555555)
+
+### Bug Fixes
+
+- typo (2222222)
+
+### Others
+
+- update readme (3333333)
+- release (4444444)
+`
+	if changelog := formatChangelog(commits); changelog != expected {
+		t.Errorf("expected changelog:\n%s\nbut got:\n%s", expected, changelog)
+	}
+}
+
+func TestChangelogSection(t *testing.T) {
+	changelog := "# Changelog\r\n\r\n## [Unreleased]\r\n\r\n## [1.2.3] - 2019-10-01\r\n### Added\r\n- bump flag\r\n\r\n## [1.2.2] - 2019-09-01\r\n### Fixed\r\n- typo\r\n\r\n[1.2.3]: https://github.com/softleader/s2i/compare/1.2.2...1.2.3\r\n"
+
+	section, found := changelogSection(changelog, "v1.2.3")
+	if !found {
+		t.Fatal("section of 1.2.3 should be found")
+	}
+	if expected := "### Added\n- bump flag"; section != expected {
+		t.Errorf("section should be %q, but got %q", expected, section)
+	}
+
+	if section, _ = changelogSection(changelog, "1.2.2"); section != "### Fixed\n- typo" {
+		t.Errorf("section of last version should exclude links, but got %q", section)
+	}
+
+	if _, found := changelogSection(changelog, "1.0.0"); found {
+		t.Error("section of 1.0.0 should not be found")
+	}
+}
@@ -8,97 +8,721 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
+)
+
+const (
+	defaultInitialVersion = "0.1.0"
+	defaultRemote         = "origin"
+	defaultTimeout        = 30 * time.Second
+	defaultFindTimeout    = 2 * time.Minute
+	defaultUserAgent      = "softleader-s2i"
 )
 
 var (
-	r = regexp.MustCompile(`\[remote "origin"\][\n|\r|\n\r|\t|\s]+url = [https://|git@]+([^@]+)?@?github.com[/:](.+)/(.+).git`)
+	clientOptions = &ClientOptions{}
+	// now 回傳目前的時間, 所有需要目前時間的地方都應透過 now 取得, 方便測試時替換成固定的時間
+	now = time.Now
+
+	// git config 的 section 及 key 不分大小寫, 如: [Remote "origin"], URL = ...
+	rs   = regexp.MustCompile(`(?i)^\[remote\s+"([^"]+)"\]`)
+	ru   = regexp.MustCompile(`(?i)^url\s*=\s*(.+)$`)
+	rscp = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
 )
 
+// ClientOptions 建立跟 github 互動的 client 時的選項
+type ClientOptions struct {
+	// BaseURL 為 GitHub Enterprise Server 的 API 位置, 如: https://github.example.com/api/v3/
+	// 空白代表使用 github.com
+	BaseURL string
+	// UploadURL 上傳 release asset 的位置, 如: https://uploads.github.example.com/
+	// 空白時由 BaseURL 推導為同 host 的 /api/uploads/, 沒有 BaseURL 時為 github.com 的 upload 位置; 可單獨指定, 如: upload 走不同的 host 或 proxy
+	UploadURL string
+	// Retry 遇到 GitHub rate limit 或暫時性錯誤時重試的選項, 預設不重試
+	Retry RetryOptions
+	// Timeout 每次送出 request 後等待 GitHub 回應的 timeout, 0 代表使用預設的 30 秒
+	// 重試時每次各自計算, 不包含重試前的等待時間, 也不包含上傳 asset 及下載 asset 內容的傳輸時間
+	Timeout time.Duration
+	// Proxy 連線到 GitHub 的 proxy url, 如: http://proxy.example.com:3128
+	// 空白代表依照 $HTTP_PROXY 及 $HTTPS_PROXY 環境變數
+	Proxy string
+	// UserAgent 跟 GitHub 互動時的 User-Agent, 如: s2i/v1.0.0, 預設為 softleader-s2i
+	UserAgent string
+	// RateLimitWarning 建立 release 後剩餘的 rate limit 低於此值時輸出警告, 0 代表不警告
+	RateLimitWarning int
+	// TokenFile 存放 token 的檔案路徑, 沒有傳入 token 時會讀取該檔案的內容做為 token
+	TokenFile string
+	// APIVersion 每個 request 帶上的 X-GitHub-Api-Version header, 如: 2022-11-28, 預設為 2022-11-28
+	APIVersion string
+	// InsecureSkipVerify 不驗證 GitHub 的 TLS 憑證, 僅供測試用的自簽憑證 GitHub Enterprise Server 使用, 切勿用於正式環境!
+	InsecureSkipVerify bool
+}
+
+// SetClientOptions 設定之後所有跟 github 互動的 client 選項, 傳入 nil 則回復預設
+func SetClientOptions(opts *ClientOptions) {
+	if opts == nil {
+		opts = &ClientOptions{}
+	}
+	clientOptions = opts
+}
+
 // NewTokenClient 建立跟 github 互動的 client
-func newTokenClient(ctx context.Context, token string) (*github.Client, error) {
+// 傳入的 token 為空時會透過 ResolveToken 找出 token
+func newTokenClient(ctx context.Context, log logrus.FieldLogger, token string) (*github.Client, error) {
+	token, err := ResolveToken(token)
+	if err != nil {
+		return nil, err
+	}
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
+	return newClient(ctx, log, ts)
+}
+
+// newClient 依照 client 選項建立以 ts 認證的 github client
+func newClient(ctx context.Context, log logrus.FieldLogger, ts oauth2.TokenSource) (*github.Client, error) {
+	transport, err := newTransport(clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	if clientOptions.InsecureSkipVerify {
+		log.Warnln("TLS certificate verification of GitHub is disabled, do NOT use it in production!")
+	}
+	version := clientOptions.APIVersion
+	if version == "" {
+		version = defaultAPIVersion
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: &apiVersionTransport{base: &loggingTransport{base: transport, log: log}, version: version}})
+	// 不設定 http.Client.Timeout, 否則會限制包含重試等待及上傳 asset 在內的總時間, timeout 改由 transport 對每次 request 各自計算
 	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc), nil
+	if clientOptions.Retry.MaxRetries > 0 || clientOptions.Retry.MaxTransientRetries > 0 {
+		tc.Transport = &retryTransport{
+			base: tc.Transport,
+			log:  log,
+			opts: clientOptions.Retry,
+		}
+	}
+	return newGitHubClient(tc)
+}
+
+// newGitHubClient 以 hc 建立 github client, 並套用 client 選項中的 BaseURL, UploadURL 及 UserAgent
+func newGitHubClient(hc *http.Client) (client *github.Client, err error) {
+	client = github.NewClient(hc)
+	if clientOptions.BaseURL != "" {
+		uploadURL := clientOptions.UploadURL
+		if uploadURL == "" {
+			if uploadURL, err = enterpriseUploadURL(clientOptions.BaseURL); err != nil {
+				return nil, err
+			}
+		}
+		if client, err = github.NewEnterpriseClient(clientOptions.BaseURL, uploadURL, hc); err != nil {
+			return nil, err
+		}
+	} else if clientOptions.UploadURL != "" {
+		if client.UploadURL, err = parseUploadURL(clientOptions.UploadURL); err != nil {
+			return nil, err
+		}
+	}
+	client.UserAgent = clientOptions.UserAgent
+	if client.UserAgent == "" {
+		client.UserAgent = defaultUserAgent
+	}
+	return client, nil
+}
+
+// enterpriseUploadURL 由 GitHub Enterprise Server 的 API 位置推導出 upload 位置, 如: https://github.example.com/api/v3/ 為 https://github.example.com/api/uploads/
+// go-github v28 的 NewEnterpriseClient 不會自行推導, 直接沿用 BaseURL 會讓上傳 asset 的 request 送到 /api/v3/ 而失敗
+func enterpriseUploadURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("requires a valid base url: %s", err)
+	}
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v3") + "/api/uploads/"
+	return u.String(), nil
+}
+
+// parseUploadURL 解析單獨指定的 upload 位置, 結尾必須為 "/" 才能正確組出 API 路徑, 沒有時自動補上
+func parseUploadURL(s string) (*url.URL, error) {
+	if !strings.HasSuffix(s, "/") {
+		s += "/"
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("requires a valid upload url: %s", err)
+	}
+	return u, nil
+}
+
+// NextVersionOptions 找下一版版號時的選項
+type NextVersionOptions struct {
+	// Bump 要增加的版號層級, 預設為 BumpPatch
+	Bump Bump
+	// InitialVersion 當 repo 尚未有任何 release 時的版號, 預設為 0.1.0
+	InitialVersion string
+	// VPrefix 當 repo 尚未有任何 release 時, 是否要在 InitialVersion 前加上 "v"
+	VPrefix bool
+	// EnforceVPrefix 是否不論 latest release 的 tag 有沒有 "v", 一律依照 VPrefix 決定下一版是否加上 "v"
+	EnforceVPrefix bool
+	// FromTags 是否以所有 tag 中 semver 最大的版號為基準, 預設以 latest release 為基準
+	FromTags bool
+	// StableOnly 是否只以正式版為基準, 開啟後即使 pre-release 的版號較大也會被忽略:
+	//
+	//  - 以 release 為基準時: 列出所有 release, 排除 draft, pre-release 及 tag 帶有 semver pre-release (如: v1.0.0-rc.1) 的 release 後, 取 semver 最大者
+	//  - FromTags 時: 排除帶有 semver pre-release 的 tag 後, 取 semver 最大者
+	StableOnly bool
+	// Channel 要找下一版的 channel, 如: beta 時只以 beta 的 tag (如: v1.2.0-beta.3) 及正式版為基準並增加 beta 的數字
+	// ChannelStable 同 StableOnly, 預設為空代表不區分 channel
+	Channel string
+	// Ref 為 BumpAuto 時, 要跟 latest release 比較 commits 的 branch, tag 或 sha, 預設為 repo 的 default branch
+	Ref string
+	// Timeout 找下一版的時限 (包含所有分頁及重試), 超過時回傳 KindTimeout 的錯誤而不會卡住 pipeline
+	// 0 代表使用預設的 2 分鐘, 小於 0 代表不設時限
+	Timeout time.Duration
+}
+
+func (o *NextVersionOptions) timeout() time.Duration {
+	if o == nil || o.Timeout == 0 {
+		return defaultFindTimeout
+	}
+	return o.Timeout
+}
+
+func (o *NextVersionOptions) stableOnly() bool {
+	return o.StableOnly || o.Channel == ChannelStable
+}
+
+func (o *NextVersionOptions) initialVersion() string {
+	v := strings.TrimPrefix(o.InitialVersion, "v")
+	if v == "" {
+		v = defaultInitialVersion
+	}
+	if o.VPrefix {
+		v = "v" + v
+	}
+	return v
+}
+
+// FindNextReleaseVersion 找下一版 revision, 也就是 latest release 依照 bump 層級增加版本號
+// 若 repo 尚未有任何 release, 則回傳 initial version; owner 或 repo 沒傳入時回傳錯誤
+func FindNextReleaseVersion(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, opts *NextVersionOptions) (string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.FindNextReleaseVersion(ctx, owner, repo, opts)
+}
+
+// FindNextReleaseVersion 同 package function FindNextReleaseVersion
+func (c *Client) FindNextReleaseVersion(ctx context.Context, owner, repo string, opts *NextVersionOptions) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	if timeout := opts.timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	next, err := findNextReleaseVersion(ctx, c.log, c.repos, owner, repo, opts)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return "", &Error{Kind: KindTimeout, Err: fmt.Errorf("finding next release version of %s/%s timed out after %s", owner, repo, opts.timeout())}
+	}
+	return next, wrapError(err)
+}
+
+func findNextReleaseVersion(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, opts *NextVersionOptions) (string, error) {
+	if opts == nil {
+		opts = &NextVersionOptions{}
+	}
+	if opts.Channel != "" && opts.Channel != ChannelStable {
+		return findNextChannelVersion(ctx, log, repos, owner, repo, opts)
+	}
+	var tag string
+	var err error
+	switch {
+	case opts.FromTags:
+		tag, err = findHighestTag(ctx, log, repos, owner, repo, opts.stableOnly())
+	case opts.stableOnly():
+		tag, err = findLatestStableReleaseTag(ctx, log, repos, owner, repo)
+	default:
+		tag, err = findLatestReleaseTag(ctx, log, repos, owner, repo)
+	}
+	if err != nil {
+		return "", err
+	}
+	if tag == "" {
+		initial := opts.initialVersion()
+		log.Debugf("%s/%s has no release yet, using initial version %s", owner, repo, initial)
+		return initial, nil
+	}
+	b := opts.Bump
+	if b == BumpAuto {
+		if b, err = inferBumpSince(ctx, log, repos, owner, repo, tag, opts.Ref); err != nil {
+			return "", err
+		}
+	}
+	next, err := NextVersion(tag, b)
+	if err != nil {
+		return "", err
+	}
+	return withVPrefix(tag, strings.TrimPrefix(next, "v"), opts), nil
 }
 
-// FindNextReleaseVersion 找下一版 revision,  也就是 latest release + 1 版本號
-func FindNextReleaseVersion(log *logrus.Logger, token, owner, repo string) (string, error) {
-	if token == "" || owner == "" || repo == "" {
-		return "", nil
+// findLatestReleaseTag 找出 latest release 的 tag, 若 repo 尚未有任何 release 則回傳空字串
+func findLatestReleaseTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) (string, error) {
+	rr, err := getLatestRelease(ctx, log, repos, owner, repo)
+	if err != nil || rr == nil {
+		return "", err
 	}
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+	return rr.GetTagName(), nil
+}
+
+// findLatestStableReleaseTag 找出所有正式版 release 中 semver 最大的 tag, 若沒有任何正式版 release 則回傳空字串
+// draft, pre-release 及 tag 帶有 semver pre-release 的 release 都會被忽略
+func findLatestStableReleaseTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) (string, error) {
+	releases, err := listReleases(ctx, log, repos, owner, repo)
 	if err != nil {
 		return "", err
 	}
+	var latest string
+	var max semver.Version
+	for _, rr := range releases {
+		tag := rr.GetTagName()
+		if rr.GetDraft() || rr.GetPrerelease() {
+			log.Debugf("skipping draft or pre-release %s", tag)
+			continue
+		}
+		sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+		if err != nil || len(sv.Pre) > 0 {
+			log.Debugf("skipping non-stable release %s", tag)
+			continue
+		}
+		if latest == "" || sv.GT(max) {
+			latest, max = tag, sv
+		}
+	}
+	if latest != "" {
+		log.Debugf("found latest stable release %s", latest)
+	}
+	return latest, nil
+}
+
+// getLatestRelease 取得 latest release, 若 repo 尚未有任何 release 則回傳 nil
+func getLatestRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) (*github.RepositoryRelease, error) {
 	log.Debugf("fetching latest release of %s/%s", owner, repo)
-	rr, _, err := client.Repositories.GetLatestRelease(ctx, owner, repo)
+	rr, _, err := repos.GetLatestRelease(ctx, owner, repo)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	log.Debugf("found %s drafted by %s published at %s", rr.GetTagName(), rr.GetAuthor().GetLogin(), rr.GetPublishedAt())
+	return rr, nil
+}
+
+// FindHighestTag 分頁列出 repo 所有的 tag, 回傳 semver 最大的 tag 及其 commit sha, 非 semver 的 tag (如: latest) 會被忽略
+// 若沒有任何 semver tag 則回傳錯誤
+func FindHighestTag(ctx context.Context, log logrus.FieldLogger, token, owner, repo string) (tag, sha string, err error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", "", err
+	}
+	return c.FindHighestTag(ctx, owner, repo)
+}
+
+// FindHighestTag 同 package function FindHighestTag
+func (c *Client) FindHighestTag(ctx context.Context, owner, repo string) (tag, sha string, err error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", "", err
+	}
+	t, err := findHighestRepositoryTag(ctx, c.log, c.repos, owner, repo, false)
+	if err != nil {
+		return "", "", wrapError(err)
+	}
+	if t == nil {
+		return "", "", &Error{Kind: KindNotFound, Err: fmt.Errorf("%s/%s has no semver tag yet", owner, repo)}
+	}
+	return t.GetName(), t.GetCommit().GetSHA(), nil
+}
+
+// findHighestTag 找出所有 tag 中 semver 最大的 tag, 非 semver 的 tag 會被忽略, 若沒有任何 semver tag 則回傳空字串
+// stable 為 true 時也會忽略帶有 semver pre-release 的 tag
+func findHighestTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, stable bool) (string, error) {
+	t, err := findHighestRepositoryTag(ctx, log, repos, owner, repo, stable)
+	if err != nil || t == nil {
+		return "", err
+	}
+	return t.GetName(), nil
+}
+
+// findHighestRepositoryTag 找出所有 tag 中 semver 最大的 tag, 若沒有任何 semver tag 則回傳 nil
+func findHighestRepositoryTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, stable bool) (*github.RepositoryTag, error) {
+	var highest *github.RepositoryTag
+	var max semver.Version
+	opt := &github.ListOptions{Page: 1, PerPage: 100}
+	for {
+		log.Debugf("fetching page %v of tags of %s/%s", opt.Page, owner, repo)
+		tags, resp, err := repos.ListTags(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			sv, err := semver.Parse(strings.TrimPrefix(t.GetName(), "v"))
+			if err != nil {
+				log.Debugf("skipping non-semver tag %s", t.GetName())
+				continue
+			}
+			if stable && len(sv.Pre) > 0 {
+				log.Debugf("skipping pre-release tag %s", t.GetName())
+				continue
+			}
+			if highest == nil || sv.GT(max) {
+				highest, max = t, sv
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	if highest != nil {
+		log.Debugf("found highest tag %s", highest.GetName())
+	}
+	return highest, nil
+}
+
+// GetDefaultBranch 回傳 repo 在 GitHub 上的 default branch
+// 適合在無法從本地 git 取得 branch 時 (如 CI 的 detached checkout), 做為 release 的 target
+func GetDefaultBranch(ctx context.Context, log logrus.FieldLogger, token, owner, repo string) (string, error) {
+	c, err := NewClient(ctx, log, token)
 	if err != nil {
 		return "", err
 	}
-	tag := rr.GetTagName()
-	log.Debugf("found %s drafted by %s published at %s", tag, rr.GetAuthor().GetLogin(), rr.GetPublishedAt())
-	version := strings.TrimPrefix(tag, "v")
-	sv, err := semver.Parse(version)
+	return c.GetDefaultBranch(ctx, owner, repo)
+}
+
+// GetDefaultBranch 同 package function GetDefaultBranch
+func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	branch, err := getDefaultBranch(ctx, c.log, c.repos, owner, repo)
+	return branch, wrapError(err)
+}
+
+func getDefaultBranch(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) (string, error) {
+	log.Debugf("fetching default branch of %s/%s", owner, repo)
+	r, _, err := repos.Get(ctx, owner, repo)
 	if err != nil {
 		return "", err
 	}
-	bumpPatch(&sv)
-	next := sv.String()
-	if strings.HasPrefix(tag, "v") {
-		next = "v" + next
+	return r.GetDefaultBranch(), nil
+}
+
+// isNotFound 判斷是否為 GitHub 回傳的 404 Not Found
+func isNotFound(err error) bool {
+	githubErr, ok := err.(*github.ErrorResponse)
+	if !ok {
+		return false
 	}
-	return next, nil
+	return githubErr.Response != nil && githubErr.Response.StatusCode == http.StatusNotFound
+}
+
+// GitRemote 代表從 git remote url 解析出的資訊
+type GitRemote struct {
+	// Host 為 remote 的 host (不含 port), 如: github.com 或 GitHub Enterprise Server 的 host
+	Host  string
+	Token string
+	Owner string
+	Repo  string
+}
+
+// Remote 回傳從 .git 中 origin remote 找到的 token, owner and repo
+func Remote(log logrus.FieldLogger, pwd string) (token, owner, repo string) {
+	return RemoteByName(log, pwd, defaultRemote)
 }
 
-func bumpPatch(sv *semver.Version) {
-	sv.Patch++
-	sv.Pre = nil
-	sv.Build = nil
+// RemoteByName 回傳從 .git 中指定 remote 找到的 token, owner and repo
+// 若指定的 remote 不存在, 則使用第一個找到的 remote
+func RemoteByName(log logrus.FieldLogger, pwd, name string) (token, owner, repo string) {
+	r := FindGitRemote(log, pwd, name)
+	return r.Token, r.Owner, r.Repo
 }
 
-// Remote 回傳從 .git 中找到的 token, owner and repo
-func Remote(log *logrus.Logger, pwd string) (token, owner, repo string) {
-	p := filepath.Join(pwd, ".git", "config")
+// FindGitRemote 回傳從 .git 中指定 remote 解析出的資訊, 若指定的 remote 不存在, 則使用第一個找到的 remote
+// 找不到任何 remote 時回傳欄位皆為空的 GitRemote
+// 預設先自行解析 .git/config, 解析不到時 (如 remote 定義在 include 的檔案中) 才改用 git 指令
+func FindGitRemote(log logrus.FieldLogger, pwd, name string) *GitRemote {
+	if !gitCLI {
+		if r := findRemoteInConfig(log, pwd, name); r.Repo != "" {
+			return r
+		}
+	}
+	return findRemoteByGit(log, pwd, name)
+}
+
+func findRemoteInConfig(log logrus.FieldLogger, pwd, name string) *GitRemote {
+	_, common := gitDir(log, pwd)
+	p := filepath.Join(common, "config")
 	log.Debugf("loading git config: %s", p)
 	b, err := ioutil.ReadFile(p)
 	if err != nil {
-		return
+		return &GitRemote{}
 	}
 	config := string(b)
-	return findRemoteOrigin(log, config)
+	return findRemote(log, config, name)
 }
 
-func findRemoteOrigin(log *logrus.Logger, config string) (token, owner, repo string) {
-	groups := r.FindStringSubmatch(config)
-	log.Debugf("found %d remote origin", len(groups)-1)
-	if len(groups) < 1 {
-		return
+func findRemoteOrigin(log logrus.FieldLogger, config string) (token, owner, repo string) {
+	r := findRemote(log, config, defaultRemote)
+	return r.Token, r.Owner, r.Repo
+}
+
+func findRemote(log logrus.FieldLogger, config, name string) *GitRemote {
+	return selectRemote(log, parseRemotes(config), name)
+}
+
+// selectRemote 從 remotes 中選出指定名稱的 remote 並解析其 url, 若指定的 remote 不存在, 則使用第一個 remote
+func selectRemote(log logrus.FieldLogger, remotes []remote, name string) *GitRemote {
+	log.Debugf("found %d remote(s)", len(remotes))
+	if len(remotes) < 1 {
+		return &GitRemote{}
+	}
+	u, found := "", false
+	for _, remote := range remotes {
+		if remote.name == name {
+			u, found = remote.url, true
+			break
+		}
+	}
+	if !found {
+		log.Debugf("remote %q not found, using the first remote %q instead", name, remotes[0].name)
+		u = remotes[0].url
+	}
+	r, ok := parseRemoteURL(u)
+	if !ok {
+		log.Debugf("unable to parse remote url: %s", u)
+		return &GitRemote{}
+	}
+	log.Debugf("found host %q, token %q, owner: %q, repo: %q", r.Host, r.Token, r.Owner, r.Repo)
+	return r
+}
+
+// ParseRepoURL 從 GitHub repo 的 url 解析出 owner 及 repo, 讓不在 git 目錄中時也能取得 owner 及 repo
+// 支援的格式同 parseRemoteURL, 如: https://github.com/softleader/s2i, git@github.com:softleader/s2i.git
+func ParseRepoURL(s string) (owner, repo string, err error) {
+	r, ok := parseRemoteURL(strings.TrimSpace(s))
+	if !ok {
+		return "", "", invalid(fmt.Errorf("unable to parse owner and repo from url: %s", s))
+	}
+	return r.Owner, r.Repo, nil
+}
+
+// parseRemoteURL 解析 git remote url, 支援以下格式:
+//
+//	https://[token@]host[:port]/owner/repo.git
+//	ssh://[user@]host[:port]/owner/repo.git
+//	[user@]host:owner/repo.git (包含 ssh config 中的 alias)
+//
+// owner/repo 之後的路徑會被忽略, 如: https://github.com/owner/repo/tree/master
+func parseRemoteURL(s string) (*GitRemote, bool) {
+	r := &GitRemote{}
+	var path string
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, false
+		}
+		r.Host = u.Hostname()
+		if u.User != nil && (u.Scheme == "https" || u.Scheme == "http") {
+			if password, ok := u.User.Password(); ok {
+				r.Token = password
+			} else {
+				r.Token = u.User.Username()
+			}
+		}
+		path = u.Path
+	} else {
+		groups := rscp.FindStringSubmatch(s)
+		if len(groups) < 1 {
+			return nil, false
+		}
+		r.Host = groups[1]
+		path = groups[2]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if r.Host == "" || len(segments) < 2 {
+		return nil, false
+	}
+	r.Owner = segments[0]
+	r.Repo = strings.TrimSuffix(segments[1], ".git")
+	if r.Owner == "" || r.Repo == "" {
+		return nil, false
+	}
+	return r, true
+}
+
+type remote struct {
+	name, url string
+}
+
+// parseRemotes 依照 git config 中出現的順序, 回傳所有 [remote "name"] 及其 url
+func parseRemotes(config string) (remotes []remote) {
+	var current string
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			current = ""
+			if groups := rs.FindStringSubmatch(line); len(groups) > 1 {
+				current = groups[1]
+			}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		if groups := ru.FindStringSubmatch(line); len(groups) > 1 {
+			remotes = append(remotes, remote{name: current, url: configValue(groups[1])})
+		}
 	}
-	token = groups[1]
-	owner = groups[2]
-	repo = groups[3]
-	log.Debugf("found token %q, owner: %q, repo: %q", token, owner, repo)
 	return
 }
 
+// configValue 去除 git config 值的引號及行尾的註解 (# 或 ;), 如: "git@github.com:a/b.git" # fork -> git@github.com:a/b.git
+func configValue(v string) string {
+	if strings.HasPrefix(v, `"`) {
+		if i := strings.Index(v[1:], `"`); i >= 0 {
+			return v[1 : i+1]
+		}
+	}
+	if i := strings.IndexAny(v, "#;"); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v)
+}
+
 // Head 回傳當前的 branch
-func Head(log *logrus.Logger, pwd string) string {
-	p := filepath.Join(pwd, ".git", "HEAD")
+// 若 HEAD 為 detached (如 CI checkout 指定的 commit), 會試著找出唯一指向該 commit 的 local branch,
+// 找不到時 head 為該 commit 的 SHA, 且 detached 為 true
+// 預設先自行解析 .git/HEAD, 解析不到時才改用 git 指令
+func Head(log logrus.FieldLogger, pwd string) (head string, detached bool) {
+	if !gitCLI {
+		if head, detached = headInGitDir(log, pwd); head != "" {
+			return
+		}
+	}
+	return headByGit(log, pwd)
+}
+
+func headInGitDir(log logrus.FieldLogger, pwd string) (head string, detached bool) {
+	dir, common := gitDir(log, pwd)
+	p := filepath.Join(dir, "HEAD")
 	log.Debugf("loading git HEAD: %s", p)
 	b, err := ioutil.ReadFile(p)
 	if err != nil {
-		return ""
+		return "", false
+	}
+	// 只取第一行, 並去除前後的空白及換行 (包含 Windows 的 \r\n)
+	line := strings.TrimSpace(strings.SplitN(string(b), "\n", 2)[0])
+	if line == "" {
+		return "", false
 	}
-	head := string(b)
-	lines := strings.Split(head, fmt.Sprintln())
-	if len(lines) < 1 {
+	if strings.HasPrefix(line, "ref: ") {
+		return strings.TrimPrefix(line, "ref: refs/heads/"), false
+	}
+	sha := line
+	log.Debugf("HEAD is detached at %s", sha)
+	if branch := findBranchOf(log, common, sha); branch != "" {
+		log.Debugf("resolved detached HEAD to branch %s", branch)
+		return branch, false
+	}
+	return sha, true
+}
+
+// findBranchOf 從 refs/heads 及 packed-refs 中找出指向 sha 的 branch, 找不到或有多個 branch 時回傳空字串
+func findBranchOf(log logrus.FieldLogger, common, sha string) string {
+	var branches []string
+	heads := filepath.Join(common, "refs", "heads")
+	filepath.Walk(heads, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil || strings.TrimSpace(string(b)) != sha {
+			return nil
+		}
+		if rel, err := filepath.Rel(heads, path); err == nil {
+			branches = append(branches, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if b, err := ioutil.ReadFile(filepath.Join(common, "packed-refs")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == sha && strings.HasPrefix(fields[1], "refs/heads/") {
+				branch := strings.TrimPrefix(fields[1], "refs/heads/")
+				if !contains(branches, branch) {
+					branches = append(branches, branch)
+				}
+			}
+		}
+	}
+	if len(branches) != 1 {
+		log.Debugf("found %d branch(es) pointing at %s", len(branches), sha)
 		return ""
 	}
-	return strings.ReplaceAll(lines[0], "ref: refs/heads/", "")
+	return branches[0]
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
+// gitDir 回傳 pwd 的 git 目錄及共用的 git 目錄 (config 所在的位置)
+// 一般的 repo 兩者皆為 .git, 在 git worktree 中 .git 為指向真正 git 目錄的檔案, 如:
+//
+//	gitdir: /path/to/main/.git/worktrees/foo
+//
+// 此時回傳該 worktree 的 git 目錄 (HEAD 所在的位置), 及其 commondir 指向的主要 git 目錄
+func gitDir(log logrus.FieldLogger, pwd string) (dir, common string) {
+	dir = filepath.Join(pwd, ".git")
+	common = dir
+	fi, err := os.Stat(dir)
+	if err != nil || fi.IsDir() {
+		return
+	}
+	b, err := ioutil.ReadFile(dir)
+	if err != nil {
+		return
+	}
+	line := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return
+	}
+	dir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(pwd, dir)
+	}
+	common = dir
+	log.Debugf("found gitdir: %s", dir)
+	if b, err = ioutil.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(b))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+		log.Debugf("found commondir: %s", common)
+	}
+	return
 }
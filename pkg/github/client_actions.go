@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// DispatchWorkflow 在 ref 上觸發 workflowFile (如: deploy.yml) 的 workflow_dispatch event, inputs 為 workflow 的輸入參數
+// workflow 不存在時回傳 KindNotFound 的錯誤, ref 不正確或 workflow 沒有設定 workflow_dispatch 時回傳 KindInvalid 的錯誤
+func DispatchWorkflow(ctx context.Context, log logrus.FieldLogger, token, owner, repo, workflowFile, ref string, inputs map[string]string) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.DispatchWorkflow(ctx, owner, repo, workflowFile, ref, inputs)
+}
+
+// DispatchWorkflow 同 package function DispatchWorkflow
+func (c *Client) DispatchWorkflow(ctx context.Context, owner, repo, workflowFile, ref string, inputs map[string]string) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(dispatchWorkflow(ctx, c.log, c.actions, owner, repo, workflowFile, ref, inputs))
+}
+
+func dispatchWorkflow(ctx context.Context, log logrus.FieldLogger, actions actionsService, owner, repo, workflowFile, ref string, inputs map[string]string) error {
+	if workflowFile == "" {
+		return invalid(fmt.Errorf("workflow file is required"))
+	}
+	if ref == "" {
+		return invalid(fmt.Errorf("ref is required"))
+	}
+	log.Debugf("dispatching workflow %s on %s of %s/%s with inputs: %v", workflowFile, ref, owner, repo, inputs)
+	_, err := actions.CreateWorkflowDispatchEventByFileName(ctx, owner, repo, workflowFile, workflowDispatchEvent{
+		Ref:    ref,
+		Inputs: inputs,
+	})
+	if err != nil {
+		if githubErr, ok := err.(*github.ErrorResponse); ok && githubErr.Response != nil {
+			switch githubErr.Response.StatusCode {
+			case http.StatusNotFound:
+				return &Error{Kind: KindNotFound, Err: fmt.Errorf("workflow %s not found in %s/%s: %s", workflowFile, owner, repo, githubErr.Message)}
+			case http.StatusUnprocessableEntity:
+				return invalid(fmt.Errorf("failed to dispatch workflow %s on %s: %s", workflowFile, ref, githubErr.Message))
+			}
+		}
+		return err
+	}
+	success(log, "Successfully dispatched workflow %s on %s", workflowFile, ref)
+	return nil
+}
+
+// DispatchRepository 觸發 repo 的 repository_dispatch event, 可用來通知下游的 repo 執行 workflow
+// payload 會被轉成 JSON 做為 event 的 client_payload, 傳入 nil 代表沒有 payload
+func DispatchRepository(ctx context.Context, log logrus.FieldLogger, token, owner, repo, eventType string, payload interface{}) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.DispatchRepository(ctx, owner, repo, eventType, payload)
+}
+
+// DispatchRepository 同 package function DispatchRepository
+func (c *Client) DispatchRepository(ctx context.Context, owner, repo, eventType string, payload interface{}) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(dispatchRepository(ctx, c.log, c.repos, owner, repo, eventType, payload))
+}
+
+func dispatchRepository(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, eventType string, payload interface{}) error {
+	if eventType == "" {
+		return invalid(fmt.Errorf("event type is required"))
+	}
+	opts := dispatchRequestOptions{EventType: eventType}
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return invalid(fmt.Errorf("failed to marshal client payload: %s", err))
+		}
+		raw := json.RawMessage(b)
+		opts.ClientPayload = &raw
+	}
+	log.Debugf("dispatching %s event to %s/%s", eventType, owner, repo)
+	if _, err := repos.Dispatch(ctx, owner, repo, opts); err != nil {
+		return err
+	}
+	success(log, "Successfully dispatched %s event to %s/%s", eventType, owner, repo)
+	return nil
+}
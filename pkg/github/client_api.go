@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"net/http"
+)
+
+// Client 持有已認證的 GitHub client, 一次要執行多個操作時建立一次即可重複使用, 省去每次呼叫都重新建立 client
+// package 中同名的 function 皆是建立 Client 後呼叫對應的 method
+type Client struct {
+	log logrus.FieldLogger
+	// opts 建立 Client 時 ClientOptions 的複本
+	opts    ClientOptions
+	repos   repositoriesService
+	git     gitService
+	actions actionsService
+	issues  issuesService
+	limits  rateLimitsService
+}
+
+// NewClient 以 token 建立可重複使用的 Client, 傳入的 token 為空時會透過 ResolveToken 找出 token
+// Client 建立時即套用當下的 ClientOptions, 之後呼叫 SetClientOptions 不會影響已建立的 Client
+func NewClient(ctx context.Context, log logrus.FieldLogger, token string) (*Client, error) {
+	opts := *clientOptions
+	client, err := newTokenClient(ctx, log, token)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	transport, err := newTransport(&opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return newClientOf(log, client, opts, &loggingTransport{base: transport, log: log}), nil
+}
+
+// NewClientWithHTTPClient 以呼叫端建立好的 hc (如: 自訂 transport, response cache 或 tracing) 建立可重複使用的 Client
+// hc 必須已自行處理認證, 只會套用 ClientOptions 中的 BaseURL, UploadURL 及 UserAgent, 其餘如 Timeout, Retry, Proxy 及 APIVersion 皆由 hc 決定
+// 下載 asset 時跟隨 redirect 到儲存空間的 request 同樣使用 hc 的 transport, 但不會帶上 Authorization header, 詳見 redirectTransport
+func NewClientWithHTTPClient(log logrus.FieldLogger, hc *http.Client) (*Client, error) {
+	if hc == nil {
+		return nil, invalid(errors.New("http client is required"))
+	}
+	opts := *clientOptions
+	client, err := newGitHubClient(hc)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return newClientOf(log, client, opts, redirectTransport(hc.Transport)), nil
+}
+
+// redirectTransport 回傳下載 asset 時跟隨 redirect 使用的 transport
+// rt 為 oauth2.Transport 時改用其 Base, 避免將 token 帶到儲存空間的預先簽署網址, 否則儲存空間會拒絕帶有其他認證的 request
+func redirectTransport(rt http.RoundTripper) http.RoundTripper {
+	if t, ok := rt.(*oauth2.Transport); ok {
+		rt = t.Base
+	}
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// newClientOf 建立 Client, download 為下載 asset 時跟隨 redirect 到儲存空間所使用不帶認證的 transport
+func newClientOf(log logrus.FieldLogger, client *github.Client, opts ClientOptions, download http.RoundTripper) *Client {
+	return &Client{
+		log:     log,
+		opts:    opts,
+		repos:   newRepositoriesService(client, log, opts, download),
+		git:     newGitService(client),
+		actions: newActionsService(client),
+		issues:  newIssuesService(client),
+		limits:  client,
+	}
+}
+
+// clientFor 建立 package function 使用的 Client, dryRun 時不會呼叫 GitHub API, 因此不需要 token
+func clientFor(ctx context.Context, log logrus.FieldLogger, token string, dryRun bool) (*Client, error) {
+	if dryRun {
+		return &Client{log: log}, nil
+	}
+	return NewClient(ctx, log, token)
+}
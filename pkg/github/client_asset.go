@@ -0,0 +1,327 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultAssetMediaType = "application/octet-stream"
+)
+
+// signatureMediaTypes detached signature 的副檔名及其 content type
+var signatureMediaTypes = map[string]string{
+	".asc":     "application/pgp-signature",
+	".sig":     "application/pgp-signature",
+	".minisig": "text/plain",
+}
+
+// UploadReleaseAsset 上傳檔案到 tag 的 release 中, 回傳上傳後的下載位置, tag 沒有 release 時回傳 KindNotFound 的錯誤
+// paths 可以是 glob pattern (如: dist/*.tar.gz), 語法同 filepath.Match, 任一 pattern 沒有匹配到檔案時回傳錯誤, 詳見 ExpandAssetPaths
+// replace 為 true 時若 release 中已有同名的 asset 會先刪除再上傳, 否則回傳 KindInvalid 的錯誤
+// GitHub 以檔名做為 asset name, 展開後有同名的檔案時不會上傳任何檔案並回傳 KindInvalid 的錯誤
+func UploadReleaseAsset(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string, paths []string, replace bool) ([]string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadReleaseAsset(ctx, owner, repo, tag, paths, replace)
+}
+
+// UploadReleaseAsset 同 package function UploadReleaseAsset
+func (c *Client) UploadReleaseAsset(ctx context.Context, owner, repo, tag string, paths []string, replace bool) ([]string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	rr, err := getReleaseByTag(ctx, c.log, c.repos, owner, repo, tag)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	urls, err := uploadReleaseAssets(ctx, c.log, c.repos, owner, repo, rr.GetID(), paths, replace)
+	return urls, wrapError(err)
+}
+
+// UploadReleaseAssetByID 上傳檔案到 release-id 的 release 中, 回傳上傳後的下載位置
+// 適合搭配 CreateRelease 回傳的 Release.ID 使用, 省去再以 tag 查詢 release 的 request, paths 及 replace 同 UploadReleaseAsset
+func UploadReleaseAssetByID(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, id int64, paths []string, replace bool) ([]string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadReleaseAssetByID(ctx, owner, repo, id, paths, replace)
+}
+
+// UploadReleaseAssetByID 同 package function UploadReleaseAssetByID
+func (c *Client) UploadReleaseAssetByID(ctx context.Context, owner, repo string, id int64, paths []string, replace bool) ([]string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	urls, err := uploadReleaseAssets(ctx, c.log, c.repos, owner, repo, id, paths, replace)
+	return urls, wrapError(err)
+}
+
+// UploadSignedReleaseAsset 上傳檔案及其 detached signature 到 tag 的 release 中, 回傳上傳後的下載位置
+// signature 須由外部先行簽署, 放在檔案旁並以 .asc, .sig 或 .minisig 為副檔名, 任一檔案找不到 signature 時不會上傳並回傳錯誤
+func UploadSignedReleaseAsset(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string, paths []string, replace bool) ([]string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadSignedReleaseAsset(ctx, owner, repo, tag, paths, replace)
+}
+
+// UploadSignedReleaseAsset 同 package function UploadSignedReleaseAsset
+func (c *Client) UploadSignedReleaseAsset(ctx context.Context, owner, repo, tag string, paths []string, replace bool) ([]string, error) {
+	paths, err := ExpandAssetPaths(c.log, paths, false)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := withSignatures(c.log, paths)
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadReleaseAsset(ctx, owner, repo, tag, signed, replace)
+}
+
+// withSignatures 在每個檔案後加上其 detached signature, 找不到 signature 時回傳 KindInvalid 錯誤
+// paths 中的 signature 檔案 (如: glob dist/* 同時匹配到的 .asc) 會被略過, 改由其對應的檔案帶上
+func withSignatures(log logrus.FieldLogger, paths []string) ([]string, error) {
+	var signed []string
+	for _, path := range paths {
+		if _, found := signatureMediaTypes[filepath.Ext(path)]; found {
+			log.Debugf("skipping signature %s, it will be uploaded along with its artifact", path)
+			continue
+		}
+		sigs := signaturesOf(path)
+		if len(sigs) == 0 {
+			return nil, invalid(fmt.Errorf("no signature found for %s", path))
+		}
+		log.Debugf("found signature of %s: %v", path, sigs)
+		signed = append(signed, path)
+		signed = append(signed, sigs...)
+	}
+	return signed, nil
+}
+
+// signaturesOf 回傳檔案旁存在的 detached signature
+func signaturesOf(path string) []string {
+	var sigs []string
+	for _, ext := range []string{".asc", ".sig", ".minisig"} {
+		if _, err := os.Stat(path + ext); err == nil {
+			sigs = append(sigs, path+ext)
+		}
+	}
+	return sigs
+}
+
+// ExpandAssetPaths 將 patterns 展開成要上傳的檔案, pattern 語法同 filepath.Match, 如: dist/*.tar.gz
+// 存在的檔案一律視為明確的路徑不做展開, 匹配到的目錄會被略過, 多個 pattern 匹配到同一個檔案時只會保留一次
+// pattern 沒有匹配到任何檔案時回傳 KindInvalid 的錯誤, allowNoMatch 為 true 時則只提出警告
+// 回傳的檔案可直接傳入 UploadReleaseAsset, 不會再被展開
+func ExpandAssetPaths(log logrus.FieldLogger, patterns []string, allowNoMatch bool) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if _, err := os.Stat(pattern); err != nil {
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, invalid(fmt.Errorf("requires a valid glob pattern %q: %s", pattern, err))
+			}
+		}
+		var files []string
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				log.Debugf("skipping directory %s matched by %s", match, pattern)
+				continue
+			}
+			files = append(files, match)
+		}
+		if len(files) == 0 {
+			if !allowNoMatch {
+				return nil, invalid(fmt.Errorf("no asset matches %s", pattern))
+			}
+			log.Warnf("no asset matches %s, skipping", pattern)
+			continue
+		}
+		log.Debugf("found %d assets matching %s", len(files), pattern)
+		for _, file := range files {
+			if key := filepath.Clean(file); !seen[key] {
+				seen[key] = true
+				paths = append(paths, file)
+			}
+		}
+	}
+	return paths, nil
+}
+
+func uploadReleaseAssets(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, id int64, patterns []string, replace bool) ([]string, error) {
+	paths, err := ExpandAssetPaths(log, patterns, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureUniqueAssetNames(paths); err != nil {
+		return nil, err
+	}
+	existing, err := listReleaseAssets(ctx, log, repos, owner, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil { // 已被取消時不再上傳剩下的檔案
+			return urls, err
+		}
+		name := filepath.Base(path)
+		if asset, found := existing[name]; found {
+			if !replace {
+				return urls, invalid(fmt.Errorf("asset %q already exists in release-id %d", name, id))
+			}
+			log.Debugf("asset %q already exists, deleting asset-id %d", name, asset.GetID())
+			if _, err := repos.DeleteReleaseAsset(ctx, owner, repo, asset.GetID()); err != nil {
+				return urls, err
+			}
+		}
+		asset, err := uploadReleaseAsset(ctx, log, repos, owner, repo, id, path)
+		if err != nil {
+			return urls, err
+		}
+		success(log, "Successfully uploaded asset: %s", asset.GetBrowserDownloadURL())
+		urls = append(urls, asset.GetBrowserDownloadURL())
+	}
+	return urls, nil
+}
+
+// ensureUniqueAssetNames 確認檔案的名稱不重複, GitHub 以檔名做為 asset name, 不同目錄的同名檔案 (如: dist/a/s2i.tar.gz 及 dist/b/s2i.tar.gz) 無法同時上傳
+func ensureUniqueAssetNames(paths []string) error {
+	seen := make(map[string]string)
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if other, found := seen[name]; found {
+			return invalid(fmt.Errorf("assets %s and %s have the same name %q", other, path, name))
+		}
+		seen[name] = path
+	}
+	return nil
+}
+
+func uploadReleaseAsset(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, id int64, path string) (*github.ReleaseAsset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	opt := &github.UploadOptions{
+		Name:      filepath.Base(path),
+		MediaType: mediaTypeOf(path),
+	}
+	log.Debugf("uploading %s (%s) to release-id %d", path, opt.MediaType, id)
+	asset, _, err := repos.UploadReleaseAsset(ctx, owner, repo, id, opt, f)
+	return asset, err
+}
+
+// listReleaseAssets 列出 release 中所有的 asset, 以 asset name 為 key
+func listReleaseAssets(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, id int64) (map[string]*github.ReleaseAsset, error) {
+	page, err := releaseAssetsOf(ctx, log, repos, owner, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	assets := make(map[string]*github.ReleaseAsset)
+	for _, asset := range page {
+		assets[asset.GetName()] = asset
+	}
+	return assets, nil
+}
+
+// releaseAssetsOf 分頁取得 release 中所有的 asset
+func releaseAssetsOf(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, id int64) ([]*github.ReleaseAsset, error) {
+	var assets []*github.ReleaseAsset
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+	for {
+		log.Debugf("fetching page %v of assets", opt.Page)
+		page, resp, err := repos.ListReleaseAssets(ctx, owner, repo, id, opt)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return assets, nil
+}
+
+// ReleaseAsset wrap GitHub Release Asset
+type ReleaseAsset struct {
+	ID                 int64
+	Name               string
+	ContentType        string
+	Size               int
+	DownloadCount      int
+	BrowserDownloadURL string
+}
+
+func newReleaseAsset(asset *github.ReleaseAsset) *ReleaseAsset {
+	return &ReleaseAsset{
+		ID:                 asset.GetID(),
+		Name:               asset.GetName(),
+		ContentType:        asset.GetContentType(),
+		Size:               asset.GetSize(),
+		DownloadCount:      asset.GetDownloadCount(),
+		BrowserDownloadURL: asset.GetBrowserDownloadURL(),
+	}
+}
+
+// GetReleaseAssets 列出 tag 的 release 中所有的 asset, 可用來確認 release 的檔案是否都已上傳
+func GetReleaseAssets(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) ([]*ReleaseAsset, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetReleaseAssets(ctx, owner, repo, tag)
+}
+
+// GetReleaseAssets 同 package function GetReleaseAssets
+func (c *Client) GetReleaseAssets(ctx context.Context, owner, repo, tag string) ([]*ReleaseAsset, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	assets, err := getReleaseAssets(ctx, c.log, c.repos, owner, repo, tag)
+	return assets, wrapError(err)
+}
+
+func getReleaseAssets(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) ([]*ReleaseAsset, error) {
+	rr, err := getReleaseByTag(ctx, log, repos, owner, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	page, err := releaseAssetsOf(ctx, log, repos, owner, repo, rr.GetID())
+	if err != nil {
+		return nil, err
+	}
+	var assets []*ReleaseAsset
+	for _, asset := range page {
+		assets = append(assets, newReleaseAsset(asset))
+	}
+	return assets, nil
+}
+
+// mediaTypeOf 依照副檔名判斷 content type, 無法判斷時為 application/octet-stream
+func mediaTypeOf(path string) string {
+	ext := filepath.Ext(path)
+	if t, found := signatureMediaTypes[ext]; found {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return defaultAssetMediaType
+}
@@ -0,0 +1,220 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMediaTypeOf(t *testing.T) {
+	for path, expected := range map[string]string{
+		"s2i.tar.gz.asc":     "application/pgp-signature",
+		"s2i.tar.gz.sig":     "application/pgp-signature",
+		"s2i.tar.gz.minisig": "text/plain",
+		"s2i":                defaultAssetMediaType,
+	} {
+		if actual := mediaTypeOf(path); actual != expected {
+			t.Errorf("media type of %s should be %s, but got %s", path, expected, actual)
+		}
+	}
+}
+
+func TestWithSignatures(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	artifact := filepath.Join(tmp, "s2i.tar.gz")
+	ioutil.WriteFile(artifact, []byte("artifact"), 0644)
+	ioutil.WriteFile(artifact+".asc", []byte("signature"), 0644)
+
+	log := logrus.StandardLogger()
+	signed, err := withSignatures(log, []string{artifact})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed) != 2 || signed[0] != artifact || signed[1] != artifact+".asc" {
+		t.Errorf("should upload artifact with its signature, but got %v", signed)
+	}
+
+	glob, err := ExpandAssetPaths(log, []string{filepath.Join(tmp, "*")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed, err = withSignatures(log, glob); err != nil {
+		t.Fatal(err)
+	}
+	if len(signed) != 2 || signed[0] != artifact || signed[1] != artifact+".asc" {
+		t.Errorf("signature matched by glob should be uploaded only once along with artifact, but got %v", signed)
+	}
+
+	unsigned := filepath.Join(tmp, "unsigned.tar.gz")
+	ioutil.WriteFile(unsigned, []byte("artifact"), 0644)
+	if _, err := withSignatures(log, []string{artifact, unsigned}); KindOf(err) != KindInvalid {
+		t.Errorf("artifact without signature should be invalid, but got %v", err)
+	}
+}
+
+func TestExpandAssetPaths(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, name := range []string{"s2i-linux.tar.gz", "s2i-darwin.tar.gz", "checksums.txt"} {
+		ioutil.WriteFile(filepath.Join(tmp, name), []byte(name), 0644)
+	}
+	os.Mkdir(filepath.Join(tmp, "tmp.tar.gz"), 0755)
+
+	log := logrus.StandardLogger()
+	checksums := filepath.Join(tmp, "checksums.txt")
+	paths, err := ExpandAssetPaths(log, []string{filepath.Join(tmp, "*.tar.gz"), checksums, filepath.Join(tmp, "*")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(tmp, "s2i-darwin.tar.gz"), filepath.Join(tmp, "s2i-linux.tar.gz"), checksums}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("paths should be %v without duplicates and directories, but got %v", expected, paths)
+	}
+
+	nothing := filepath.Join(tmp, "*.zip")
+	if _, err := ExpandAssetPaths(log, []string{nothing}, false); KindOf(err) != KindInvalid {
+		t.Errorf("pattern matches nothing should be invalid, but got %v", err)
+	}
+	if paths, err = ExpandAssetPaths(log, []string{nothing, checksums}, true); err != nil || len(paths) != 1 {
+		t.Errorf("pattern matches nothing should be skipped when allowed, but got %v (%v)", paths, err)
+	}
+	if _, err := ExpandAssetPaths(log, []string{filepath.Join(tmp, "[")}, false); KindOf(err) != KindInvalid {
+		t.Errorf("malformed pattern should be invalid, but got %v", err)
+	}
+}
+
+func TestUploadReleaseAssetReplace(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	artifact := filepath.Join(tmp, "s2i.tar.gz")
+	ioutil.WriteFile(artifact, []byte("artifact"), 0644)
+
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.3")})
+	repos.assets = map[int64][]*github.ReleaseAsset{7: {{ID: github.Int64(70), Name: github.String("s2i.tar.gz")}}}
+	c := &Client{log: logrus.StandardLogger(), repos: repos}
+	ctx := context.Background()
+
+	if _, err := c.UploadReleaseAsset(ctx, "softleader", "s2i", "v1.2.3", []string{artifact}, false); KindOf(err) != KindInvalid {
+		t.Errorf("existing asset without replace should be invalid, but got %v", err)
+	}
+	if len(repos.uploaded) != 0 {
+		t.Errorf("should not upload when asset already exists, but uploaded %v", repos.uploaded)
+	}
+
+	urls, err := c.UploadReleaseAsset(ctx, "softleader", "s2i", "v1.2.3", []string{artifact}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.removed) != 1 || repos.removed[0] != 70 {
+		t.Errorf("existing asset-id 70 should be deleted before uploading, but got %v", repos.removed)
+	}
+	if len(urls) != 1 || len(repos.uploaded) != 1 || repos.uploaded[0] != "s2i.tar.gz" {
+		t.Errorf("asset should be uploaded again, but got %v", urls)
+	}
+
+	if _, err := c.UploadReleaseAsset(ctx, "softleader", "s2i", "v0.0.1", []string{artifact}, true); KindOf(err) != KindNotFound {
+		t.Errorf("missing release should be not found, but got %v", err)
+	}
+}
+
+func TestUploadReleaseAssetCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var uploaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/softleader/s2i/releases/7/assets":
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			b, _ := ioutil.ReadAll(r.Body)
+			uploaded = append(uploaded, r.URL.Query().Get("name")+":"+string(b))
+			cancel() // 模擬第一個檔案上傳後 CI job 被取消
+			fmt.Fprintf(w, `{"browser_download_url":"https://github.com/softleader/s2i/releases/download/v1.2.3/%s"}`, r.URL.Query().Get("name"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	SetClientOptions(&ClientOptions{BaseURL: server.URL + "/", UploadURL: server.URL + "/"})
+	c, err := NewClientWithHTTPClient(logrus.StandardLogger(), http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "s2i-upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	if _, err := c.UploadReleaseAssetByID(ctx, "softleader", "s2i", 7, paths, false); !isCanceled(err) {
+		t.Errorf("upload should be cancelled, but got %v", err)
+	}
+	if len(uploaded) != 1 || uploaded[0] != "a.txt:a.txt" {
+		t.Errorf("only the first asset should be uploaded, but got %v", uploaded)
+	}
+
+	r := &contextReader{ctx: ctx, r: strings.NewReader("content")}
+	if _, err := r.Read(make([]byte, 7)); err != context.Canceled {
+		t.Errorf("reading after cancelled should be context.Canceled, but got %v", err)
+	}
+}
+
+// isCanceled 判斷 err 是否為包裝過的 context.Canceled
+func isCanceled(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Err == context.Canceled
+}
+
+func TestUploadReleaseAssetSameName(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	for _, dir := range []string{"a", "b"} {
+		os.MkdirAll(filepath.Join(tmp, dir), 0755)
+		ioutil.WriteFile(filepath.Join(tmp, dir, "s2i.tar.gz"), []byte(dir), 0644)
+	}
+
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.3")})
+	c := &Client{log: logrus.StandardLogger(), repos: repos}
+	for _, replace := range []bool{false, true} {
+		if _, err := c.UploadReleaseAsset(context.Background(), "softleader", "s2i", "v1.2.3", []string{filepath.Join(tmp, "*", "s2i.tar.gz")}, replace); KindOf(err) != KindInvalid {
+			t.Errorf("assets with the same name should be invalid (replace: %v), but got %v", replace, err)
+		}
+	}
+	if len(repos.uploaded) != 0 || len(repos.removed) != 0 {
+		t.Errorf("should not upload or delete any asset, but uploaded %v and deleted %v", repos.uploaded, repos.removed)
+	}
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+const (
+	defaultConcurrency = 4
+)
+
+// ReleaseTarget 批次建立 release 時, 每個 repo 要建立的 release
+type ReleaseTarget struct {
+	Owner  string
+	Repo   string
+	Branch string // 也可以是 commit sha
+	Tag    string
+}
+
+// ReleaseResult 批次建立 release 時, 每個 repo 的結果, 失敗時 Err 不為 nil
+type ReleaseResult struct {
+	Target  ReleaseTarget
+	Release *Release
+	Err     error
+}
+
+// CreateReleases 以最多 concurrency 個 worker 同時建立多個 repo 的 release, concurrency 小於 1 時為 4
+// 任一 repo 失敗不會中斷其他 repo, 回傳的結果順序同 targets
+func CreateReleases(ctx context.Context, log logrus.FieldLogger, token string, targets []ReleaseTarget, concurrency int, opts *ReleaseOptions) ([]*ReleaseResult, error) {
+	c, err := clientFor(ctx, log, token, opts.dryRun())
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateReleases(ctx, targets, concurrency, opts), nil
+}
+
+// CreateReleases 同 package function CreateReleases
+func (c *Client) CreateReleases(ctx context.Context, targets []ReleaseTarget, concurrency int, opts *ReleaseOptions) []*ReleaseResult {
+	return createReleases(ctx, c.log, c.repos, c.git, targets, concurrency, opts)
+}
+
+func createReleases(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, targets []ReleaseTarget, concurrency int, opts *ReleaseOptions) []*ReleaseResult {
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+	results := make([]*ReleaseResult, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = createReleaseOf(ctx, log, repos, git, targets[i], opts)
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func createReleaseOf(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, t ReleaseTarget, opts *ReleaseOptions) *ReleaseResult {
+	result := &ReleaseResult{Target: t}
+	if err := validateRelease(t.Owner, t.Repo, t.Branch, t.Tag); err != nil {
+		result.Err = err
+		return result
+	}
+	if err := validateMakeLatest(opts); err != nil {
+		result.Err = err
+		return result
+	}
+	if opts.dryRun() {
+		result.Release = simulate(log, t.Owner, t.Repo, newRepositoryRelease(t.Branch, t.Tag, opts))
+		return result
+	}
+	release, err := createRelease(ctx, log, repos, git, t.Owner, t.Repo, t.Branch, t.Tag, opts)
+	if err != nil {
+		log.Debugf("failed to create release %s for %s/%s: %s", t.Tag, t.Owner, t.Repo, err)
+	}
+	result.Release, result.Err = release, wrapError(err)
+	return result
+}
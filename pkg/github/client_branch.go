@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+)
+
+// ListBranches 列出 repo 中符合 matcher 的 branch 名稱, 如: 以 NewGlobMatcher 列出所有 release/* 再搭配 DeleteBranch 清除
+// matcher 為 nil 時列出所有 branch
+func ListBranches(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, matcher TagMatcher) ([]string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListBranches(ctx, owner, repo, matcher)
+}
+
+// ListBranches 同 package function ListBranches
+func (c *Client) ListBranches(ctx context.Context, owner, repo string, matcher TagMatcher) ([]string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	branches, err := listBranches(ctx, c.log, c.repos, owner, repo, matcher)
+	return branches, wrapError(err)
+}
+
+func listBranches(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, matcher TagMatcher) ([]string, error) {
+	var branches []string
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+	for {
+		log.Debugf("fetching page %v of branches", opt.Page)
+		page, resp, err := repos.ListBranches(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, branch := range page {
+			if name := branch.GetName(); matcher == nil || matcher.Matches(name) {
+				branches = append(branches, name)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	log.Debugf("found %d matched branches in %s/%s", len(branches), owner, repo)
+	return branches, nil
+}
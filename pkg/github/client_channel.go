@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/blang/semver"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	// ChannelStable 正式版的 channel, 只以正式版為基準, 同 NextVersionOptions.StableOnly
+	ChannelStable = "stable"
+)
+
+// findNextChannelVersion 找出 channel (如: beta) 的下一版, tag 格式為 <version>-<channel>.<n>, 如: v1.2.0-beta.3
+//
+//   - channel 中最大的版號大於所有正式版時: 增加 channel 的數字, 如: v1.2.0-beta.3 -> v1.2.0-beta.4
+//   - 否則以正式版依照 bump 層級增加版號後開始新的 channel, 如: v1.2.0 -> v1.2.1-beta.1
+//   - 都沒有時以 initial version 開始, 如: 0.1.0-beta.1
+func findNextChannelVersion(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, opts *NextVersionOptions) (string, error) {
+	if _, err := semver.NewPRVersion(opts.Channel); err != nil {
+		return "", invalid(fmt.Errorf("invalid channel %q: %s", opts.Channel, err))
+	}
+	tags, err := listVersionTags(ctx, log, repos, owner, repo, opts.FromTags)
+	if err != nil {
+		return "", err
+	}
+	stable, pre := highestOfChannel(log, tags, opts.Channel)
+	if pre != "" && (stable == "" || mustParse(pre).GT(mustParse(stable))) {
+		sv := mustParse(pre)
+		sv.Pre = bumpPrerelease(sv.Pre)
+		sv.Build = nil
+		return withVPrefix(pre, sv.String(), opts), nil
+	}
+	var sv semver.Version
+	base := stable
+	if stable == "" {
+		base = opts.initialVersion()
+		log.Debugf("%s/%s has no stable release yet, using initial version %s", owner, repo, base)
+		if sv, err = semver.Parse(strings.TrimPrefix(base, "v")); err != nil {
+			return "", err
+		}
+	} else {
+		sv = mustParse(stable)
+		b := opts.Bump
+		if b == BumpAuto {
+			if b, err = inferBumpSince(ctx, log, repos, owner, repo, stable, opts.Ref); err != nil {
+				return "", err
+			}
+		}
+		if err := bump(&sv, b); err != nil {
+			return "", err
+		}
+	}
+	sv.Pre = []semver.PRVersion{{VersionStr: opts.Channel}, {VersionNum: 1, IsNum: true}}
+	return withVPrefix(base, sv.String(), opts), nil
+}
+
+// listVersionTags 列出 release (排除 draft) 的 tag, fromTags 為 true 時改為列出所有的 tag
+func listVersionTags(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, fromTags bool) ([]string, error) {
+	var tags []string
+	if !fromTags {
+		releases, err := listReleases(ctx, log, repos, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range releases {
+			if !rr.GetDraft() {
+				tags = append(tags, rr.GetTagName())
+			}
+		}
+		return tags, nil
+	}
+	opt := &github.ListOptions{Page: 1, PerPage: 100}
+	for {
+		log.Debugf("fetching page %v of tags of %s/%s", opt.Page, owner, repo)
+		page, resp, err := repos.ListTags(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			tags = append(tags, t.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	return tags, nil
+}
+
+// highestOfChannel 回傳 tags 中 semver 最大的正式版, 以及 pre-release 第一個識別字為 channel 中 semver 最大者
+func highestOfChannel(log logrus.FieldLogger, tags []string, channel string) (stable, pre string) {
+	var maxStable, maxPre semver.Version
+	for _, tag := range tags {
+		sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+		if err != nil {
+			log.Debugf("skipping non-semver tag %s", tag)
+			continue
+		}
+		switch {
+		case len(sv.Pre) == 0:
+			if stable == "" || sv.GT(maxStable) {
+				stable, maxStable = tag, sv
+			}
+		case !sv.Pre[0].IsNum && sv.Pre[0].VersionStr == channel:
+			if pre == "" || sv.GT(maxPre) {
+				pre, maxPre = tag, sv
+			}
+		default:
+			log.Debugf("skipping tag %s of other channel", tag)
+		}
+	}
+	log.Debugf("found highest stable %q and %s %q", stable, channel, pre)
+	return
+}
+
+// mustParse 解析已確認過是 semver 的 tag
+func mustParse(tag string) semver.Version {
+	return semver.MustParse(strings.TrimPrefix(tag, "v"))
+}
+
+// withVPrefix 依照 tag 是否有 "v" 決定 next 是否加上 "v", EnforceVPrefix 時一律依照 VPrefix
+func withVPrefix(tag, next string, opts *NextVersionOptions) string {
+	vprefix := strings.HasPrefix(tag, "v")
+	if opts.EnforceVPrefix {
+		vprefix = opts.VPrefix
+	}
+	if vprefix {
+		return "v" + next
+	}
+	return next
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+)
+
+// CommentOnCommit 在 commit 上留言, body 支援 markdown, 回傳留言的網址
+func CommentOnCommit(ctx context.Context, log logrus.FieldLogger, token, owner, repo, sha, body string) (string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.CommentOnCommit(ctx, owner, repo, sha, body)
+}
+
+// CommentOnCommit 同 package function CommentOnCommit
+func (c *Client) CommentOnCommit(ctx context.Context, owner, repo, sha, body string) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	if sha == "" {
+		return "", invalid(errors.New("sha is required"))
+	}
+	u, err := commentOnCommit(ctx, c.log, c.repos, owner, repo, sha, body)
+	return u, wrapError(err)
+}
+
+func commentOnCommit(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, sha, body string) (string, error) {
+	log.Debugf("commenting on commit %s of %s/%s", sha, owner, repo)
+	comment, _, err := repos.CreateComment(ctx, owner, repo, sha, &github.RepositoryComment{Body: github.String(body)})
+	if err != nil {
+		return "", err
+	}
+	return comment.GetHTMLURL(), nil
+}
+
+// commentReleasedCommit 在 release 的 tag 所指向的 commit 上留言連結到 release
+func commentReleasedCommit(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo string, release *github.RepositoryRelease) error {
+	sha, err := resolveTagCommit(ctx, log, git, owner, repo, release.GetTagName())
+	if err != nil {
+		return err
+	}
+	u, err := commentOnCommit(ctx, log, repos, owner, repo, sha, fmt.Sprintf("Released as %s: %s", release.GetTagName(), release.GetHTMLURL()))
+	if err != nil {
+		return err
+	}
+	success(log, "Successfully commented on released commit: %s", u)
+	return nil
+}
@@ -0,0 +1,209 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// InferBump 比較 tag 到 ref 之間的 commits, 依照 Conventional Commits 判斷下一版要增加的版號層級
+// ref 若不傳入則為 repo 的 default branch, 沒有任何 commit 符合規範時回傳 BumpPatch
+func InferBump(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag, ref string) (Bump, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.InferBump(ctx, owner, repo, tag, ref)
+}
+
+// InferBump 同 package function InferBump
+func (c *Client) InferBump(ctx context.Context, owner, repo, tag, ref string) (Bump, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	b, err := inferBumpSince(ctx, c.log, c.repos, owner, repo, tag, ref)
+	return b, wrapError(err)
+}
+
+func inferBumpSince(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag, ref string) (Bump, error) {
+	if ref == "" {
+		var err error
+		if ref, err = getDefaultBranch(ctx, log, repos, owner, repo); err != nil {
+			return "", err
+		}
+	}
+	commits, err := compareCommits(ctx, log, repos, owner, repo, tag, ref)
+	if err != nil {
+		return "", err
+	}
+	var messages []string
+	for _, c := range commits {
+		messages = append(messages, c.GetCommit().GetMessage())
+	}
+	b := inferBump(messages)
+	log.Debugf("inferred %s bump from %d commit(s)", b, len(messages))
+	return b, nil
+}
+
+// Commit 代表一個 commit 的摘要資訊
+type Commit struct {
+	SHA     string
+	Author  string
+	Message string
+}
+
+// newCommit 將 github commit 轉換成 Commit, Author 優先使用 GitHub 帳號, 沒有時使用 git 的 author name
+func newCommit(rc *github.RepositoryCommit) *Commit {
+	author := rc.GetAuthor().GetLogin()
+	if author == "" {
+		author = rc.GetCommit().GetAuthor().GetName()
+	}
+	return &Commit{
+		SHA:     rc.GetSHA(),
+		Author:  author,
+		Message: rc.GetCommit().GetMessage(),
+	}
+}
+
+// CommitsSinceLatestRelease 回傳 latest release 到 ref 之間的 commits, 可在建立新版前預覽將包含的內容
+// ref 若不傳入則為 repo 的 default branch, repo 尚未有任何 release 時回傳 ref 上所有的 commits
+func CommitsSinceLatestRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, ref string) ([]*Commit, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.CommitsSinceLatestRelease(ctx, owner, repo, ref)
+}
+
+// CommitsSinceLatestRelease 同 package function CommitsSinceLatestRelease
+func (c *Client) CommitsSinceLatestRelease(ctx context.Context, owner, repo, ref string) ([]*Commit, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	commits, err := commitsSinceLatestRelease(ctx, c.log, c.repos, owner, repo, ref)
+	return commits, wrapError(err)
+}
+
+func commitsSinceLatestRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, ref string) ([]*Commit, error) {
+	if ref == "" {
+		var err error
+		if ref, err = getDefaultBranch(ctx, log, repos, owner, repo); err != nil {
+			return nil, err
+		}
+	}
+	tag, err := findLatestReleaseTag(ctx, log, repos, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var rcs []*github.RepositoryCommit
+	if tag == "" {
+		log.Debugf("%s/%s has no release yet, listing all commits of %s", owner, repo, ref)
+		rcs, err = listCommits(ctx, log, repos, owner, repo, ref)
+	} else {
+		rcs, err = compareCommits(ctx, log, repos, owner, repo, tag, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var commits []*Commit
+	for _, rc := range rcs {
+		commits = append(commits, newCommit(rc))
+	}
+	return commits, nil
+}
+
+// listCommits 分頁取得 ref 上所有的 commits
+func listCommits(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, ref string) ([]*github.RepositoryCommit, error) {
+	var commits []*github.RepositoryCommit
+	opt := &github.CommitsListOptions{
+		SHA: ref,
+		ListOptions: github.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+	for {
+		log.Debugf("fetching page %v of commits", opt.Page)
+		page, resp, err := repos.ListCommits(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	return commits, nil
+}
+
+// compareCommits 分頁取得 base...head 之間所有的 commits
+func compareCommits(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, base, head string) ([]*github.RepositoryCommit, error) {
+	log.Debugf("comparing commits of %s/%s between %s...%s", owner, repo, base, head)
+	var commits []*github.RepositoryCommit
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+	for {
+		log.Debugf("fetching page %v of commits", opt.Page)
+		cc, resp, err := repos.CompareCommits(ctx, owner, repo, base, head, opt)
+		if err != nil {
+			return nil, err
+		}
+		for i := range cc.Commits {
+			commits = append(commits, &cc.Commits[i])
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	return commits, nil
+}
+
+// ResolveRef 將 ref (branch, tag 或縮寫的 commit sha) 解析成完整 40 碼的 commit sha, 可記錄 release 實際釘選的 commit
+// ref 不存在時回傳 KindNotFound 的錯誤
+func ResolveRef(ctx context.Context, log logrus.FieldLogger, token, owner, repo, ref string) (string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.ResolveRef(ctx, owner, repo, ref)
+}
+
+// ResolveRef 同 package function ResolveRef
+func (c *Client) ResolveRef(ctx context.Context, owner, repo, ref string) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	if ref == "" {
+		return "", invalid(errors.New("ref is required"))
+	}
+	sha, err := resolveRef(ctx, c.log, c.repos, owner, repo, ref)
+	return sha, wrapError(err)
+}
+
+func resolveRef(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, ref string) (string, error) {
+	log.Debugf("resolving commit sha of %s in %s/%s", ref, owner, repo)
+	sha, _, err := repos.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		// 找不到 ref 時 GitHub 回傳 404, 縮寫的 sha 無法對應到 commit 時回傳 422
+		if githubErr, ok := err.(*github.ErrorResponse); ok && githubErr.Response != nil {
+			if code := githubErr.Response.StatusCode; code == http.StatusNotFound || code == http.StatusUnprocessableEntity {
+				return "", &Error{Kind: KindNotFound, Err: fmt.Errorf("ref %s not found in %s/%s", ref, owner, repo)}
+			}
+		}
+		return "", err
+	}
+	if !rsha.MatchString(sha) {
+		return "", fmt.Errorf("unexpected commit sha of %s: %q", ref, sha)
+	}
+	log.Debugf("resolved %s to %s", ref, sha)
+	return sha, nil
+}
@@ -2,45 +2,499 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"github.com/blang/semver"
 	"github.com/google/go-github/v28/github"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
 )
 
-// CreateRelease 建立 github 的 release
-func CreateRelease(log *logrus.Logger, token, owner, repo, branch, tag string) (*Release, error) {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+// ReleaseOptions 建立 release 時的選項, 傳入 nil 代表皆使用預設值
+type ReleaseOptions struct {
+	// Name release 的標題, 預設為空
+	Name string
+	// Body release 的說明, 支援 markdown, 預設為空
+	Body string
+	// Draft 建立尚未發佈的 draft release, 之後可透過 PublishRelease 發佈
+	Draft bool
+	// DryRun 只印出將要建立的 release, 不會真的呼叫 GitHub API
+	DryRun bool
+	// TagMessage 不為空時, 會先以此訊息建立 annotated tag 再建立 release, 否則由 GitHub 建立 lightweight tag
+	TagMessage string
+	// Tagger annotated tag 的 tagger, 預設為 token 的使用者
+	Tagger *github.CommitAuthor
+	// RequireNewer 拒絕建立版號沒有大於 latest release 的 release, DryRun 時不檢查
+	// 建立 pre-release 時也會拒絕小於同一版號中已存在的 pre-release, 且無法列出 release 時也視為錯誤
+	RequireNewer bool
+	// CheckPrereleaseOrder 建立 pre-release 時檢查是否小於同一版號中已存在的 pre-release, 只輸出警告不影響建立
+	// 檢查需要列出所有的 release, 因此預設不檢查, 設定 RequireNewer 時一定會檢查
+	CheckPrereleaseOrder bool
+	// Idempotent tag 已有 release 且 target 與要建立的相同時, 直接回傳既有的 release 而不視為錯誤, 讓 pipeline 可以安全地重跑
+	// 建立 pre-release 時不能與 force 同時使用
+	Idempotent bool
+	// ExistingTag tag 已存在 (如: CI 已透過 git push) 時, 建立的 release 直接綁定該 tag, 不指定 target 也不再建立 annotated tag
+	// tag 不存在時則同一般的方式以 branch 建立, 建立 pre-release 時不能與 force 同時使用, 避免刪除 CI 推送的 tag
+	ExistingTag bool
+	// GenerateReleaseNotes 由 GitHub 依照上一個 tag 之後 merged 的 PR 自動產生 release notes, 有設定 Body 時會接在 Body 之後
+	GenerateReleaseNotes bool
+	// RequireSignedCommit 拒絕為簽章沒有通過 GitHub 驗證的 commit 建立 release
+	RequireSignedCommit bool
+	// RefuseArchived 建立前先確認 repo 沒有被 archived, 被 archived 時回傳清楚的錯誤訊息
+	RefuseArchived bool
+	// MakeLatest 是否將 release 標示為 latest, 可以是 MakeLatestTrue, MakeLatestFalse 或 MakeLatestLegacy, 預設為空由 GitHub 決定
+	// 在舊的 major 版本 backport 修正時可設為 MakeLatestFalse, 避免 latest 被舊版本取代
+	MakeLatest string
+	// CommentOnCommit 建立成功後在 release 的 commit 上留言連結到 release, 建立 pre-release 時也適用
+	// 留言失敗不影響 release 的建立, 錯誤會放在回傳的 Release.CommentErr 中; draft release 的 tag 尚未建立, 因此不會留言
+	CommentOnCommit bool
+	// DiscussionCategory 不為空時, 會在該 category 中建立連結到 release 的 discussion, repo 必須已啟用 discussions
+	DiscussionCategory string
+	// VerifyBranch 建立前先確認 branch 存在, 否則 GitHub 會默默地改以 default branch 建立 tag; branch 為 commit sha 時不檢查
+	VerifyBranch bool
+}
+
+const (
+	// MakeLatestTrue 將 release 標示為 latest
+	MakeLatestTrue = "true"
+	// MakeLatestFalse 不將 release 標示為 latest
+	MakeLatestFalse = "false"
+	// MakeLatestLegacy 由 GitHub 依照建立時間及 semver 決定是否為 latest
+	MakeLatestLegacy = "legacy"
+)
+
+func (o *ReleaseOptions) dryRun() bool {
+	return o != nil && o.DryRun
+}
+
+func (o *ReleaseOptions) annotated() bool {
+	return o != nil && o.TagMessage != ""
+}
+
+func (o *ReleaseOptions) requireNewer() bool {
+	return o != nil && o.RequireNewer
+}
+
+func (o *ReleaseOptions) checkPrereleaseOrder() bool {
+	return o != nil && (o.CheckPrereleaseOrder || o.RequireNewer)
+}
+
+func (o *ReleaseOptions) idempotent() bool {
+	return o != nil && o.Idempotent
+}
+
+func (o *ReleaseOptions) existingTag() bool {
+	return o != nil && o.ExistingTag
+}
+
+func (o *ReleaseOptions) refuseArchived() bool {
+	return o != nil && o.RefuseArchived
+}
+
+func (o *ReleaseOptions) requireSignedCommit() bool {
+	return o != nil && o.RequireSignedCommit
+}
+
+func (o *ReleaseOptions) generateReleaseNotes() bool {
+	return o != nil && o.GenerateReleaseNotes
+}
+
+func (o *ReleaseOptions) commentOnCommit() bool {
+	return o != nil && o.CommentOnCommit && !o.Draft
+}
+
+func (o *ReleaseOptions) verifyBranch() bool {
+	return o != nil && o.VerifyBranch
+}
+
+func (o *ReleaseOptions) discussionCategory() string {
+	if o == nil {
+		return ""
+	}
+	return o.DiscussionCategory
+}
+
+func (o *ReleaseOptions) makeLatest() string {
+	if o == nil {
+		return ""
+	}
+	return o.MakeLatest
+}
+
+// validateMakeLatest 確認 MakeLatest 為 GitHub 支援的值
+func validateMakeLatest(opts *ReleaseOptions) error {
+	switch opts.makeLatest() {
+	case "", MakeLatestTrue, MakeLatestFalse, MakeLatestLegacy:
+		return nil
+	}
+	return invalid(fmt.Errorf("unsupported make-latest: %q, must be one of %s, %s or %s", opts.makeLatest(), MakeLatestTrue, MakeLatestFalse, MakeLatestLegacy))
+}
+
+// validatePrereleaseOptions 確認選項可以用在 pre-release
+// force 會刪除既有的 release 及 tag, 與 Idempotent 沿用既有 release 及 ExistingTag 沿用既有 tag 的行為互相衝突
+func validatePrereleaseOptions(force bool, opts *ReleaseOptions) error {
+	if force && opts.idempotent() {
+		return invalid(fmt.Errorf("force and idempotent cannot be used together"))
+	}
+	if force && opts.existingTag() {
+		return invalid(fmt.Errorf("force and existing-tag cannot be used together"))
+	}
+	return nil
+}
+
+// submitRelease 依照選項呼叫 GitHub API 建立 release
+func submitRelease(ctx context.Context, repos repositoriesService, owner, repo string, r *github.RepositoryRelease, opts *ReleaseOptions) (*github.RepositoryRelease, *github.Response, error) {
+	if opts.generateReleaseNotes() || opts.makeLatest() != "" || opts.discussionCategory() != "" {
+		rr, resp, err := repos.CreateReleaseRequest(ctx, owner, repo, &releaseRequest{
+			RepositoryRelease:      r,
+			GenerateReleaseNotes:   opts.generateReleaseNotes(),
+			MakeLatest:             opts.makeLatest(),
+			DiscussionCategoryName: opts.discussionCategory(),
+		})
+		if err != nil && opts.discussionCategory() != "" && isDiscussionCategoryError(err) {
+			return nil, resp, invalid(fmt.Errorf("discussion category %q does not exist or discussions are not enabled in %s/%s: %s", opts.discussionCategory(), owner, repo, err))
+		}
+		return rr, resp, err
+	}
+	return repos.CreateRelease(ctx, owner, repo, r)
+}
+
+// isDiscussionCategoryError 判斷是否為 discussion category 不存在或 repo 沒有啟用 discussions 的錯誤
+func isDiscussionCategoryError(err error) bool {
+	githubErr, ok := err.(*github.ErrorResponse)
+	if !ok || githubErr.Response == nil || githubErr.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	if strings.Contains(strings.ToLower(githubErr.Message), "discussion") {
+		return true
+	}
+	for _, e := range githubErr.Errors {
+		if strings.Contains(strings.ToLower(e.Field+e.Message), "discussion") {
+			return true
+		}
+	}
+	return false
+}
+
+// findExistingRelease 找出 tag 已建立的 release, 不存在時回傳 nil
+// 已存在但 target 與 branch 不同時回傳 KindInvalid 錯誤, 避免誤把不同 commit 的 release 當成重跑的結果
+func findExistingRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, branch, tag string) (*github.RepositoryRelease, error) {
+	log.Debugf("checking if release %s already exists in %s/%s", tag, owner, repo)
+	rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
 	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
+	if target := rr.GetTargetCommitish(); target != branch {
+		return nil, invalid(fmt.Errorf("release %s already exists in %s/%s but targets %s instead of %s", tag, owner, repo, target, branch))
+	}
+	return rr, nil
+}
+
+// ensureBranchExists 確認 branch 存在, branch 為 commit sha 時不檢查
+func ensureBranchExists(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, branch string) error {
+	if rsha.MatchString(branch) {
+		log.Debugf("skipping branch check of commit %s", branch)
+		return nil
+	}
+	log.Debugf("checking if branch %s exists in %s/%s", branch, owner, repo)
+	if _, _, err := repos.GetBranch(ctx, owner, repo, branch); err != nil {
+		if isNotFound(err) {
+			return &Error{Kind: KindNotFound, Err: fmt.Errorf("branch %s not found in %s/%s, please check the branch name", branch, owner, repo)}
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureNewer 確認 tag 的版號大於 latest release, repo 尚未有任何 release 時直接通過
+func ensureNewer(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) error {
+	latest, err := findLatestReleaseTag(ctx, log, repos, owner, repo)
+	if err != nil || latest == "" {
+		return err
+	}
+	newer, err := IsNewer(tag, latest)
+	if err != nil {
+		return invalid(err)
+	}
+	if !newer {
+		return invalid(fmt.Errorf("tag %s is not newer than the latest release %s", tag, latest))
+	}
+	return nil
+}
+
+// ensurePrereleaseOrder 確認 tag 的 pre-release 大於同一版號中所有已存在的 pre-release, 如: 已有 v1.2.0-rc.3 時不能建立 v1.2.0-rc.2
+// tag 不是 semver 或不是 pre-release 時不檢查, 與 tag 相同的 release 也會被略過 (如: force 重建)
+func ensurePrereleaseOrder(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) error {
+	sv, err := semver.Parse(strings.TrimPrefix(tag, "v"))
+	if err != nil || len(sv.Pre) == 0 {
+		log.Debugf("skipping pre-release order check of %s", tag)
+		return nil
+	}
+	releases, err := listReleases(ctx, log, repos, owner, repo)
+	if err != nil {
+		return err
+	}
+	for _, rr := range releases {
+		existing, err := semver.Parse(strings.TrimPrefix(rr.GetTagName(), "v"))
+		if err != nil || len(existing.Pre) == 0 || !sameCore(sv, existing) {
+			continue
+		}
+		if existing.GT(sv) {
+			return invalid(fmt.Errorf("pre-release %s already exists, %s would go backwards", rr.GetTagName(), tag))
+		}
+	}
+	return nil
+}
+
+// sameCore 判斷兩個版號的 major, minor 及 patch 是否相同
+func sameCore(a, b semver.Version) bool {
+	return a.Major == b.Major && a.Minor == b.Minor && a.Patch == b.Patch
+}
+
+// simulate 印出將要建立的 release, 並回傳尚未建立的 release 資訊
+func simulate(log logrus.FieldLogger, owner, repo string, r *github.RepositoryRelease) *Release {
+	log.Printf("[dry-run] Would create release %s for %s/%s branch: %s (pre-release: %v, draft: %v)", r.GetTagName(), owner, repo, r.GetTargetCommitish(), r.GetPrerelease(), r.GetDraft())
+	return newRelease(r)
+}
+
+// newRepositoryRelease 依照選項產生要建立的 github release
+func newRepositoryRelease(branch, tag string, opts *ReleaseOptions) *github.RepositoryRelease {
 	r := &github.RepositoryRelease{
 		TagName:         &tag,
 		TargetCommitish: &branch,
 	}
-	log.Debugf("creating release %s for %s/%s branch: %s", tag, owner, repo, branch)
-	release, _, err := client.Repositories.CreateRelease(ctx, owner, repo, r)
+	if opts == nil {
+		return r
+	}
+	if opts.Name != "" {
+		r.Name = &opts.Name
+	}
+	if opts.Body != "" {
+		r.Body = &opts.Body
+	}
+	if opts.Draft {
+		r.Draft = &opts.Draft
+	}
+	return r
+}
+
+// CreateRelease 建立 github 的 release
+// branch 也可以傳入 commit sha, 將 release 固定在該 commit, 避免建立前 branch 又有新的 commit
+func CreateRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, branch, tag string, opts *ReleaseOptions) (*Release, error) {
+	c, err := clientFor(ctx, log, token, opts.dryRun())
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateRelease(ctx, owner, repo, branch, tag, opts)
+}
+
+// CreateRelease 同 package function CreateRelease
+func (c *Client) CreateRelease(ctx context.Context, owner, repo, branch, tag string, opts *ReleaseOptions) (*Release, error) {
+	if err := validateRelease(owner, repo, branch, tag); err != nil {
+		return nil, err
+	}
+	if err := validateMakeLatest(opts); err != nil {
+		return nil, err
+	}
+	if opts.dryRun() {
+		return simulate(c.log, owner, repo, newRepositoryRelease(branch, tag, opts)), nil
+	}
+	release, err := createRelease(ctx, c.log, c.repos, c.git, owner, repo, branch, tag, opts)
+	return release, wrapError(err)
+}
+
+func createRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo, branch, tag string, opts *ReleaseOptions) (*Release, error) {
+	if opts.refuseArchived() {
+		if err := ensureNotArchived(ctx, log, repos, owner, repo); err != nil {
+			return nil, err
+		}
+	}
+	if opts.idempotent() {
+		existing, err := findExistingRelease(ctx, log, repos, owner, repo, branch, tag)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			log.Printf("Release %s already exists, skipping: %s", tag, existing.GetHTMLURL())
+			return newRelease(existing), nil
+		}
+	}
+	if opts.requireNewer() {
+		if err := ensureNewer(ctx, log, repos, owner, repo, tag); err != nil {
+			return nil, err
+		}
+	}
+	exists := false
+	if opts.existingTag() {
+		var err error
+		if exists, err = tagExists(ctx, log, git, owner, repo, tag); err != nil {
+			return nil, err
+		}
+	}
+	if opts.verifyBranch() && !exists {
+		if err := ensureBranchExists(ctx, log, repos, owner, repo, branch); err != nil {
+			return nil, err
+		}
+	}
+	if opts.requireSignedCommit() {
+		if err := ensureSigned(ctx, log, git, owner, repo, branch, tag, exists); err != nil {
+			return nil, err
+		}
+	}
+	if opts.annotated() && !exists {
+		if err := createAnnotatedTag(ctx, log, git, owner, repo, branch, tag, opts); err != nil {
+			return nil, err
+		}
+	}
+	r := newRepositoryRelease(branch, tag, opts)
+	if exists {
+		log.Debugf("tag %s already exists, creating release bound to it without target", tag)
+		r.TargetCommitish = nil
+	}
+	log.Debugf("creating release %s for %s/%s branch: %s", tag, owner, repo, r.GetTargetCommitish())
+	release, _, err := submitRelease(ctx, repos, owner, repo, r, opts)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Successfully created release: %s", release.GetHTMLURL())
-	return newRelease(release), nil
+	success(log, "Successfully created release: %s", release.GetHTMLURL())
+	return commentOnReleased(ctx, log, repos, git, owner, repo, release, opts), nil
+}
+
+// commentOnReleased 設定 CommentOnCommit 時在 release 的 commit 上留言, 失敗時輸出警告並將錯誤放在回傳的 Release.CommentErr 中
+func commentOnReleased(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo string, rr *github.RepositoryRelease, opts *ReleaseOptions) *Release {
+	release := newRelease(rr)
+	if opts.commentOnCommit() {
+		if err := commentReleasedCommit(ctx, log, repos, git, owner, repo, rr); err != nil {
+			log.Warnf("Failed to comment on the released commit of %s: %s", rr.GetTagName(), err)
+			release.CommentErr = wrapError(err)
+		}
+	}
+	return release
 }
 
-// CreatePrerelease 建立 github 的 pre-release
-func CreatePrerelease(log *logrus.Logger, token, owner, repo, branch, tag string, force bool) (*Release, error) {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+// CreatePrerelease 建立 github 的 pre-release, branch 同 CreateRelease 也可以傳入 commit sha
+// force 時會先刪除已存在的 release 及 tag 再重建, 但已存在的 release 的 target 與 branch 不同時會拒絕刪除並回傳 KindInvalid 的錯誤
+func CreatePrerelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, branch, tag string, force bool, opts *ReleaseOptions) (*Release, error) {
+	c, err := clientFor(ctx, log, token, opts.dryRun())
 	if err != nil {
 		return nil, err
 	}
+	return c.CreatePrerelease(ctx, owner, repo, branch, tag, force, opts)
+}
+
+// CreatePrerelease 同 package function CreatePrerelease
+func (c *Client) CreatePrerelease(ctx context.Context, owner, repo, branch, tag string, force bool, opts *ReleaseOptions) (*Release, error) {
+	if err := validateRelease(owner, repo, branch, tag); err != nil {
+		return nil, err
+	}
+	if err := validateMakeLatest(opts); err != nil {
+		return nil, err
+	}
+	if err := validatePrereleaseOptions(force, opts); err != nil {
+		return nil, err
+	}
+	if opts.dryRun() {
+		r := newRepositoryRelease(branch, tag, opts)
+		r.Prerelease = github.Bool(true)
+		return simulate(c.log, owner, repo, r), nil
+	}
+	release, err := createPrerelease(ctx, c.log, c.repos, c.git, owner, repo, branch, tag, force, opts)
+	return release, wrapError(err)
+}
+
+// CreateEnvironmentPrerelease 以 base 版號及環境名稱組成的 tag (如: v1.2.0-staging) 建立 pre-release, tag 的規則同 EnvironmentTag
+// 其餘參數同 CreatePrerelease
+func CreateEnvironmentPrerelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, branch, base, env string, force bool, opts *ReleaseOptions) (*Release, error) {
+	c, err := clientFor(ctx, log, token, opts.dryRun())
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateEnvironmentPrerelease(ctx, owner, repo, branch, base, env, force, opts)
+}
+
+// CreateEnvironmentPrerelease 同 package function CreateEnvironmentPrerelease
+func (c *Client) CreateEnvironmentPrerelease(ctx context.Context, owner, repo, branch, base, env string, force bool, opts *ReleaseOptions) (*Release, error) {
+	tag, err := EnvironmentTag(base, env)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return c.CreatePrerelease(ctx, owner, repo, branch, tag, force, opts)
+}
+
+func createPrerelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo, branch, tag string, force bool, opts *ReleaseOptions) (*Release, error) {
+	if opts.refuseArchived() {
+		if err := ensureNotArchived(ctx, log, repos, owner, repo); err != nil {
+			return nil, err
+		}
+	}
+	if opts.idempotent() {
+		existing, err := findExistingRelease(ctx, log, repos, owner, repo, branch, tag)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			log.Printf("Pre-release %s already exists, skipping: %s", tag, existing.GetHTMLURL())
+			return newRelease(existing), nil
+		}
+	}
+	if opts.requireNewer() {
+		if err := ensureNewer(ctx, log, repos, owner, repo, tag); err != nil {
+			return nil, err
+		}
+	}
+	// 沒有要求 RequireNewer 時檢查只是提醒, 無法列出 release (如: 權限不足或 rate limit) 也不應阻擋建立 pre-release
+	if opts.checkPrereleaseOrder() {
+		if err := ensurePrereleaseOrder(ctx, log, repos, owner, repo, tag); err != nil {
+			if opts.requireNewer() {
+				return nil, err
+			}
+			if KindOf(err) == KindInvalid {
+				log.Warnln(err)
+			} else {
+				log.Warnf("Unable to check the order of pre-release %s: %s", tag, err)
+			}
+		}
+	}
+	exists := false
+	if opts.existingTag() {
+		var err error
+		if exists, err = tagExists(ctx, log, git, owner, repo, tag); err != nil {
+			return nil, err
+		}
+	}
+	if opts.verifyBranch() && !exists {
+		if err := ensureBranchExists(ctx, log, repos, owner, repo, branch); err != nil {
+			return nil, err
+		}
+	}
+	if opts.requireSignedCommit() {
+		if err := ensureSigned(ctx, log, git, owner, repo, branch, tag, exists); err != nil {
+			return nil, err
+		}
+	}
+	if opts.annotated() && !exists {
+		if force {
+			log.Debugf("force to delete release and tag %s before creating annotated tag", tag)
+			if err := forceDeleteReleaseAndTag(ctx, log, repos, git, owner, repo, branch, tag); err != nil {
+				return nil, err
+			}
+		}
+		if err := createAnnotatedTag(ctx, log, git, owner, repo, branch, tag, opts); err != nil {
+			return nil, err
+		}
+	}
 	pre := true
-	r := &github.RepositoryRelease{
-		TagName:         &tag,
-		TargetCommitish: &branch,
-		Prerelease:      &pre,
+	r := newRepositoryRelease(branch, tag, opts)
+	r.Prerelease = &pre
+	if exists {
+		log.Debugf("tag %s already exists, creating pre-release bound to it without target", tag)
+		r.TargetCommitish = nil
 	}
-	log.Debugf("creating pre-release %s for %s/%s branch: %s", tag, owner, repo, branch)
-	release, _, err := client.Repositories.CreateRelease(ctx, owner, repo, r)
+	log.Debugf("creating pre-release %s for %s/%s branch: %s", tag, owner, repo, r.GetTargetCommitish())
+	release, _, err := submitRelease(ctx, repos, owner, repo, r, opts)
 	if err != nil {
 		githubErr, ok := err.(*github.ErrorResponse)
 		if !ok {
@@ -48,25 +502,46 @@ func CreatePrerelease(log *logrus.Logger, token, owner, repo, branch, tag string
 		}
 		if force && isTagNameAlreadyExists(githubErr.Errors) {
 			log.Debugf("tag name %s already exists, force to delete it..", tag)
-			if err := deleteReleaseAndTag(ctx, log, client, owner, repo, tag, false); err != nil {
+			if err := forceDeleteReleaseAndTag(ctx, log, repos, git, owner, repo, branch, tag); err != nil {
 				return nil, err
 			}
 		}
 		log.Debugf("creating pre-release %s again for %s/%s branch: %s", tag, owner, repo, branch)
-		if release, _, err = client.Repositories.CreateRelease(ctx, owner, repo, r); err != nil {
+		if release, _, err = submitRelease(ctx, repos, owner, repo, r, opts); err != nil {
 			return nil, err
 		}
 	}
 
-	log.Printf("Successfully created pre-release: %s", release.GetHTMLURL())
-	return newRelease(release), nil
+	success(log, "Successfully created pre-release: %s", release.GetHTMLURL())
+	return commentOnReleased(ctx, log, repos, git, owner, repo, release, opts), nil
 }
 
+// forceDeleteReleaseAndTag force 時刪除已存在的 release 及 tag
+// 已存在的 release 的 target 與 branch 不同時拒絕刪除, 避免不同 branch 剛好使用相同的 tag 時誤刪了其他 branch 的 release
+func forceDeleteReleaseAndTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo, branch, tag string) error {
+	if _, err := findExistingRelease(ctx, log, repos, owner, repo, branch, tag); err != nil {
+		if KindOf(err) == KindInvalid {
+			log.Warnf("Refusing to force delete release %s which belongs to another branch!", tag)
+		}
+		return err
+	}
+	return deleteReleaseAndTag(ctx, log, repos, git, owner, repo, tag, false)
+}
+
+// isTagNameAlreadyExists 判斷是否為 tag 已存在的錯誤, 除了 tag_name 的 already_exists 外
+// GitHub 有時會回傳其他欄位 (如: published_at) 或 custom code, 此時以 message 是否提到 tag 已存在來判斷
 func isTagNameAlreadyExists(errors []github.Error) bool {
 	for _, err := range errors {
 		if err.Field == "tag_name" && err.Code == "already_exists" {
 			return true
 		}
+		if err.Code == "already_exists" && err.Resource == "Release" {
+			return true
+		}
+		message := strings.ToLower(err.Message)
+		if strings.Contains(message, "tag") && strings.Contains(message, "already") {
+			return true
+		}
 	}
 	return false
 }
@@ -0,0 +1,28 @@
+package github
+
+import (
+	"github.com/google/go-github/v28/github"
+	"testing"
+)
+
+func TestIsTagNameAlreadyExists(t *testing.T) {
+	for _, errors := range [][]github.Error{
+		{{Resource: "Release", Field: "tag_name", Code: "already_exists"}},
+		{{Resource: "Release", Field: "published_at", Code: "already_exists"}},
+		{{Resource: "Release", Code: "custom", Message: "Tag name already exists"}},
+		{{Code: "invalid"}, {Code: "custom", Message: "tag v1.0.0 is already taken"}},
+	} {
+		if !isTagNameAlreadyExists(errors) {
+			t.Errorf("%v should be recognized as tag name already exists", errors)
+		}
+	}
+	for _, errors := range [][]github.Error{
+		nil,
+		{{Resource: "Release", Field: "target_commitish", Code: "invalid"}},
+		{{Code: "custom", Message: "Published releases must have a valid tag"}},
+	} {
+		if isTagNameAlreadyExists(errors) {
+			t.Errorf("%v should not be recognized as tag name already exists", errors)
+		}
+	}
+}
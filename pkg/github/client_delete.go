@@ -2,26 +2,40 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/go-github/v28/github"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
 )
 
 // DeleteMatchesReleasesAndTags 刪除所有符合的 release 及其 tag
-func DeleteMatchesReleasesAndTags(log *logrus.Logger, token, owner, repo string, matcher TagMatcher, dryRun bool) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+func DeleteMatchesReleasesAndTags(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, matcher TagMatcher, dryRun bool) error {
+	c, err := NewClient(ctx, log, token)
 	if err != nil {
 		return err
 	}
+	return c.DeleteMatchesReleasesAndTags(ctx, owner, repo, matcher, dryRun)
+}
+
+// DeleteMatchesReleasesAndTags 同 package function DeleteMatchesReleasesAndTags
+func (c *Client) DeleteMatchesReleasesAndTags(ctx context.Context, owner, repo string, matcher TagMatcher, dryRun bool) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(deleteMatchesReleasesAndTags(ctx, c.log, c.repos, c.git, owner, repo, matcher, dryRun))
+}
 
+func deleteMatchesReleasesAndTags(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo string, matcher TagMatcher, dryRun bool) error {
 	opt := &github.ListOptions{
 		Page:    1,
 		PerPage: 100,
 	}
 	for {
 		log.Debugf("fetching page %v of tags", opt.Page)
-		tags, resp, err := client.Repositories.ListTags(ctx, owner, repo, opt)
+		tags, resp, err := repos.ListTags(ctx, owner, repo, opt)
 		if err != nil {
 			return err
 		}
@@ -29,7 +43,7 @@ func DeleteMatchesReleasesAndTags(log *logrus.Logger, token, owner, repo string,
 			if name := tag.GetName(); len(name) > 0 {
 				if matcher.Matches(name) {
 					log.Infof("'%s' matches! start to delete it...", name)
-					if err := deleteReleaseAndTag(ctx, log, client, owner, repo, name, dryRun); err != nil {
+					if err := deleteReleaseAndTag(ctx, log, repos, git, owner, repo, name, dryRun); err != nil {
 						return err
 					}
 					log.Infof("'%s' has been deleted from GitHub", name)
@@ -47,32 +61,192 @@ func DeleteMatchesReleasesAndTags(log *logrus.Logger, token, owner, repo string,
 }
 
 // DeleteReleasesAndTags 刪除多筆 release 及其 refs/tag
-func DeleteReleasesAndTags(log *logrus.Logger, token, owner, repo string, tags []string, dryRun bool) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+func DeleteReleasesAndTags(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, tags []string, dryRun bool) error {
+	c, err := NewClient(ctx, log, token)
 	if err != nil {
 		return err
 	}
+	return c.DeleteReleasesAndTags(ctx, owner, repo, tags, dryRun)
+}
+
+// DeleteReleasesAndTags 同 package function DeleteReleasesAndTags
+func (c *Client) DeleteReleasesAndTags(ctx context.Context, owner, repo string, tags []string, dryRun bool) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
 	for _, tag := range tags {
-		if err := deleteReleaseAndTag(ctx, log, client, owner, repo, tag, dryRun); err != nil {
-			return err
+		if err := deleteReleaseAndTag(ctx, c.log, c.repos, c.git, owner, repo, tag, dryRun); err != nil {
+			return wrapError(err)
 		}
 	}
 	return nil
 }
 
+// DeleteRelease 刪除 tag 的 release 及其 refs/tag, tag 不存在時回傳錯誤
+func DeleteRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string, dryRun bool) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.DeleteRelease(ctx, owner, repo, tag, dryRun)
+}
+
+// DeleteRelease 同 package function DeleteRelease
+func (c *Client) DeleteRelease(ctx context.Context, owner, repo, tag string, dryRun bool) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(deleteReleaseWithTag(ctx, c.log, c.repos, c.git, owner, repo, tag, dryRun))
+}
+
+func deleteReleaseWithTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo, tag string, dryRun bool) error {
+	exists, err := tagExists(ctx, log, git, owner, repo, tag)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &Error{Kind: KindNotFound, Err: fmt.Errorf("tag %q does not exist in %s/%s", tag, owner, repo)}
+	}
+	if err := deleteReleaseAndTag(ctx, log, repos, git, owner, repo, tag, dryRun); err != nil {
+		return err
+	}
+	if dryRun {
+		log.Printf("[dry-run] Would delete release and tag: %s", tag)
+		return nil
+	}
+	success(log, "Successfully deleted release and tag: %s", tag)
+	return nil
+}
+
+// DeleteReleaseOnly 只刪除 tag 的 release, 保留其 refs/tag, release 不存在時回傳錯誤
+func DeleteReleaseOnly(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string, dryRun bool) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.DeleteReleaseOnly(ctx, owner, repo, tag, dryRun)
+}
+
+// DeleteReleaseOnly 同 package function DeleteReleaseOnly
+func (c *Client) DeleteReleaseOnly(ctx context.Context, owner, repo, tag string, dryRun bool) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(deleteReleaseOnly(ctx, c.log, c.repos, owner, repo, tag, dryRun))
+}
+
+func deleteReleaseOnly(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string, dryRun bool) error {
+	log.Debugf("fetching release-id of tag '%s'", tag)
+	rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil {
+		if isNotFound(err) {
+			return &Error{Kind: KindNotFound, Err: fmt.Errorf("release %s not found in %s/%s", tag, owner, repo)}
+		}
+		return err
+	}
+	if dryRun {
+		log.Printf("[dry-run] Would delete release: %s", tag)
+		return nil
+	}
+	log.Debugf("deleting release %s by release-id %d", tag, rr.GetID())
+	if _, err := repos.DeleteRelease(ctx, owner, repo, rr.GetID()); err != nil {
+		return err
+	}
+	success(log, "Successfully deleted release and kept tag: %s", tag)
+	return nil
+}
+
+// PrunePrereleases 刪除所有發佈超過 olderThan 的 pre-release 及其 refs/tag, 回傳被刪除的 tag
+// dryRun 為 true 時只回傳將被刪除的 tag, 不會真的刪除
+func PrunePrereleases(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, olderThan time.Duration, dryRun bool) ([]string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.PrunePrereleases(ctx, owner, repo, olderThan, dryRun)
+}
+
+// PrunePrereleases 同 package function PrunePrereleases
+func (c *Client) PrunePrereleases(ctx context.Context, owner, repo string, olderThan time.Duration, dryRun bool) ([]string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	tags, err := prunePrereleases(ctx, c.log, c.repos, c.git, owner, repo, olderThan, dryRun)
+	return tags, wrapError(err)
+}
+
+func prunePrereleases(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo string, olderThan time.Duration, dryRun bool) ([]string, error) {
+	releases, err := listReleases(ctx, log, repos, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	before := now().Add(-olderThan)
+	var pruned []string
+	for _, rr := range releases {
+		if !rr.GetPrerelease() || rr.PublishedAt == nil || !rr.GetPublishedAt().Before(before) {
+			continue
+		}
+		tag := rr.GetTagName()
+		log.Debugf("pre-release %s published at %s is older than %s", tag, rr.GetPublishedAt(), olderThan)
+		if err := deleteReleaseAndTag(ctx, log, repos, git, owner, repo, tag, dryRun); err != nil {
+			return pruned, err
+		}
+		if dryRun {
+			log.Printf("[dry-run] Would delete pre-release and tag: %s", tag)
+		} else {
+			success(log, "Successfully deleted pre-release and tag: %s", tag)
+		}
+		pruned = append(pruned, tag)
+	}
+	return pruned, nil
+}
+
+// TagExists 判斷 tag 是否已存在於 repo 中
+func TagExists(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) (bool, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return false, err
+	}
+	return c.TagExists(ctx, owner, repo, tag)
+}
+
+// TagExists 同 package function TagExists
+func (c *Client) TagExists(ctx context.Context, owner, repo, tag string) (bool, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return false, err
+	}
+	exists, err := tagExists(ctx, c.log, c.git, owner, repo, tag)
+	return exists, wrapError(err)
+}
+
+// tagExists 判斷 refs/tags/<tag> 是否存在
+func tagExists(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, tag string) (bool, error) {
+	log.Debugf("fetching refs/tags/%s of %s/%s", tag, owner, repo)
+	ref, resp, err := git.GetRef(ctx, owner, repo, fmt.Sprintf("tags/%s", tag))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		if _, ok := err.(*github.ErrorResponse); !ok && resp != nil && resp.StatusCode == http.StatusOK {
+			return false, nil // 代表只有部分符合的 refs, 如: v1 符合 v1.0.0 及 v1.1.0
+		}
+		return false, err
+	}
+	return ref.GetRef() == fmt.Sprintf("refs/tags/%s", tag), nil
+}
+
 // DeleteReleaseAndTag 刪除 release 及其 refs/tag
-func deleteReleaseAndTag(ctx context.Context, log *logrus.Logger, client *github.Client, owner, repo, tag string, dryRun bool) error {
-	if err := deleteRelease(ctx, log, client, owner, repo, tag, dryRun); err != nil {
+func deleteReleaseAndTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, git gitService, owner, repo, tag string, dryRun bool) error {
+	if err := deleteRelease(ctx, log, repos, owner, repo, tag, dryRun); err != nil {
 		return err
 	}
-	return deleteTag(ctx, log, client, owner, repo, tag, dryRun)
+	return deleteTag(ctx, log, git, owner, repo, tag, dryRun)
 }
 
-func deleteTag(ctx context.Context, log *logrus.Logger, client *github.Client, owner, repo, tag string, dryRun bool) error {
+func deleteTag(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, tag string, dryRun bool) error {
 	log.Debugf("deleting refs/tags %s", tag)
 	if !dryRun {
-		_, err := client.Git.DeleteRef(ctx, owner, repo, fmt.Sprintf("tags/%s", tag))
+		_, err := git.DeleteRef(ctx, owner, repo, fmt.Sprintf("tags/%s", tag))
 		if err != nil {
 			githubErr, ok := err.(*github.ErrorResponse)
 			if !ok {
@@ -86,9 +260,95 @@ func deleteTag(ctx context.Context, log *logrus.Logger, client *github.Client, o
 	}
 	return nil
 }
-func deleteRelease(ctx context.Context, log *logrus.Logger, client *github.Client, owner, repo, tag string, dryRun bool) error {
+
+// DeleteTag 只刪除 refs/tags/tag, 不會刪除 release, 適合清除沒有 release 的 tag (如: 誤推的 tag)
+// tag 不存在時回傳 KindNotFound 的錯誤
+func DeleteTag(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string, dryRun bool) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.DeleteTag(ctx, owner, repo, tag, dryRun)
+}
+
+// DeleteTag 同 package function DeleteTag
+func (c *Client) DeleteTag(ctx context.Context, owner, repo, tag string, dryRun bool) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	if tag == "" {
+		return invalid(errors.New("tag is required"))
+	}
+	return wrapError(deleteTagOnly(ctx, c.log, c.git, owner, repo, tag, dryRun))
+}
+
+func deleteTagOnly(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, tag string, dryRun bool) error {
+	exists, err := tagExists(ctx, log, git, owner, repo, tag)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &Error{Kind: KindNotFound, Err: fmt.Errorf("tag %q does not exist in %s/%s", tag, owner, repo)}
+	}
+	if dryRun {
+		log.Printf("[dry-run] Would delete tag: %s", tag)
+		return nil
+	}
+	log.Debugf("deleting refs/tags/%s of %s/%s", tag, owner, repo)
+	if _, err := git.DeleteRef(ctx, owner, repo, fmt.Sprintf("tags/%s", tag)); err != nil {
+		if githubErr, ok := err.(*github.ErrorResponse); ok && githubErr.Response.StatusCode == http.StatusUnprocessableEntity {
+			return &Error{Kind: KindNotFound, Err: fmt.Errorf("tag %q does not exist in %s/%s", tag, owner, repo)}
+		}
+		return err
+	}
+	success(log, "Successfully deleted tag: %s", tag)
+	return nil
+}
+
+// DeleteBranch 刪除 repo 的 branch, 如: release 後清除 release/* branch
+// branch 不存在時回傳 KindNotFound, branch 受保護無法刪除時回傳 KindInvalid 的錯誤
+func DeleteBranch(ctx context.Context, log logrus.FieldLogger, token, owner, repo, branch string) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.DeleteBranch(ctx, owner, repo, branch)
+}
+
+// DeleteBranch 同 package function DeleteBranch
+func (c *Client) DeleteBranch(ctx context.Context, owner, repo, branch string) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	if branch == "" {
+		return invalid(errors.New("branch is required"))
+	}
+	return wrapError(deleteBranch(ctx, c.log, c.git, owner, repo, branch))
+}
+
+func deleteBranch(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, branch string) error {
+	log.Debugf("deleting refs/heads/%s of %s/%s", branch, owner, repo)
+	_, err := git.DeleteRef(ctx, owner, repo, fmt.Sprintf("heads/%s", branch))
+	if err != nil {
+		githubErr, ok := err.(*github.ErrorResponse)
+		if !ok {
+			return err
+		}
+		if strings.Contains(strings.ToLower(githubErr.Message), "protected") {
+			return invalid(fmt.Errorf("branch %s of %s/%s is protected and cannot be deleted", branch, owner, repo))
+		}
+		if code := githubErr.Response.StatusCode; code == http.StatusNotFound || code == http.StatusUnprocessableEntity {
+			return &Error{Kind: KindNotFound, Err: fmt.Errorf("branch %s not found in %s/%s", branch, owner, repo)}
+		}
+		return err
+	}
+	success(log, "Successfully deleted branch: %s", branch)
+	return nil
+}
+
+func deleteRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string, dryRun bool) error {
 	log.Debugf("fetching release-id of tag '%s'", tag)
-	rr, _, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+	rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
 	if err != nil {
 		githubErr, ok := err.(*github.ErrorResponse)
 		if !ok {
@@ -101,7 +361,7 @@ func deleteRelease(ctx context.Context, log *logrus.Logger, client *github.Clien
 	}
 	log.Debugf("deleting release %s by release-id %d", tag, rr.GetID())
 	if !dryRun {
-		_, err = client.Repositories.DeleteRelease(ctx, owner, repo, rr.GetID())
+		_, err = repos.DeleteRelease(ctx, owner, repo, rr.GetID())
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+)
+
+// DeploymentState 代表 deployment 的狀態
+type DeploymentState string
+
+const (
+	// DeploymentInProgress 部署中
+	DeploymentInProgress DeploymentState = "in_progress"
+	// DeploymentSuccess 部署成功
+	DeploymentSuccess DeploymentState = "success"
+	// DeploymentFailure 部署失敗
+	DeploymentFailure DeploymentState = "failure"
+	// DeploymentError 部署過程發生錯誤
+	DeploymentError DeploymentState = "error"
+)
+
+// CreateDeployment 在 GitHub 上建立 ref 部署到 environment 的 deployment, 回傳 deployment id
+// 因為通常是在剛建立 release 後就部署, 所以不會等待 ref 的 commit status 檢查, 也不會自動 merge default branch
+func CreateDeployment(ctx context.Context, log logrus.FieldLogger, token, owner, repo, ref, environment string) (int64, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return 0, err
+	}
+	return c.CreateDeployment(ctx, owner, repo, ref, environment)
+}
+
+// CreateDeployment 同 package function CreateDeployment
+func (c *Client) CreateDeployment(ctx context.Context, owner, repo, ref, environment string) (int64, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return 0, err
+	}
+	id, err := createDeployment(ctx, c.log, c.repos, owner, repo, ref, environment)
+	return id, wrapError(err)
+}
+
+func createDeployment(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, ref, environment string) (int64, error) {
+	if ref == "" {
+		return 0, invalid(fmt.Errorf("ref is required"))
+	}
+	if environment == "" {
+		return 0, invalid(fmt.Errorf("environment is required"))
+	}
+	log.Debugf("creating deployment of %s to %s for %s/%s", ref, environment, owner, repo)
+	d, _, err := repos.CreateDeployment(ctx, owner, repo, &github.DeploymentRequest{
+		Ref:              github.String(ref),
+		Environment:      github.String(environment),
+		AutoMerge:        github.Bool(false),
+		RequiredContexts: &[]string{},
+	})
+	if err != nil {
+		return 0, err
+	}
+	success(log, "Successfully created deployment %d of %s to %s", d.GetID(), ref, environment)
+	return d.GetID(), nil
+}
+
+// CreateDeploymentStatus 更新 deployment 的狀態
+func CreateDeploymentStatus(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, id int64, state DeploymentState) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.CreateDeploymentStatus(ctx, owner, repo, id, state)
+}
+
+// CreateDeploymentStatus 同 package function CreateDeploymentStatus
+func (c *Client) CreateDeploymentStatus(ctx context.Context, owner, repo string, id int64, state DeploymentState) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(createDeploymentStatus(ctx, c.log, c.repos, owner, repo, id, state))
+}
+
+func createDeploymentStatus(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, id int64, state DeploymentState) error {
+	switch state {
+	case DeploymentInProgress, DeploymentSuccess, DeploymentFailure, DeploymentError:
+	default:
+		return invalid(fmt.Errorf("unsupported deployment state: %q", state))
+	}
+	log.Debugf("marking deployment %d of %s/%s as %s", id, owner, repo, state)
+	_, _, err := repos.CreateDeploymentStatus(ctx, owner, repo, id, &github.DeploymentStatusRequest{
+		State: github.String(string(state)),
+	})
+	if err != nil {
+		return err
+	}
+	success(log, "Successfully marked deployment %d as %s", id, state)
+	return nil
+}
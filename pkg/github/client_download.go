@@ -0,0 +1,103 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// AssetDownload 下載 release asset 時, 每個 asset 的結果, 失敗時 Err 不為 nil
+type AssetDownload struct {
+	Asset *ReleaseAsset
+	Path  string
+	Err   error
+}
+
+// DownloadReleaseAssets 以最多 concurrency 個 worker 同時下載 tag 的 release 中所有的 asset 到 dir, 檔名同 asset name
+// dir 不存在時會自動建立, concurrency 小於 1 時為 4; 任一 asset 失敗不會中斷其他 asset, 回傳的結果順序同 release 中的 asset
+func DownloadReleaseAssets(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag, dir string, concurrency int) ([]*AssetDownload, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.DownloadReleaseAssets(ctx, owner, repo, tag, dir, concurrency)
+}
+
+// DownloadReleaseAssets 同 package function DownloadReleaseAssets
+func (c *Client) DownloadReleaseAssets(ctx context.Context, owner, repo, tag, dir string, concurrency int) ([]*AssetDownload, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	assets, err := getReleaseAssets(ctx, c.log, c.repos, owner, repo, tag)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return downloadReleaseAssets(ctx, c.log, c.repos, owner, repo, assets, dir, concurrency), nil
+}
+
+func downloadReleaseAssets(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, assets []*ReleaseAsset, dir string, concurrency int) []*AssetDownload {
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+	results := make([]*AssetDownload, len(assets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = downloadReleaseAsset(ctx, log, repos, owner, repo, assets[i], dir)
+			}
+		}()
+	}
+	for i := range assets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func downloadReleaseAsset(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, asset *ReleaseAsset, dir string) *AssetDownload {
+	result := &AssetDownload{Asset: asset, Path: filepath.Join(dir, filepath.Base(asset.Name))}
+	log.Debugf("downloading asset %q (asset-id %d) to %s", asset.Name, asset.ID, result.Path)
+	rc, err := repos.DownloadReleaseAssetContent(ctx, owner, repo, asset.ID)
+	if err != nil {
+		result.Err = wrapError(err)
+		log.Errorf("Failed to download asset %s: %s", asset.Name, err)
+		return result
+	}
+	defer rc.Close()
+	if result.Err = writeFile(result.Path, rc); result.Err != nil {
+		log.Errorf("Failed to download asset %s: %s", asset.Name, result.Err)
+		return result
+	}
+	success(log, "Successfully downloaded asset: %s", result.Path)
+	return result
+}
+
+// writeFile 將 r 的內容寫入 path, 寫入失敗時會刪除寫了一半的檔案
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write %s: %s", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadReleaseAssets(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/softleader/s2i/releases/tags/v1.2.3":
+			fmt.Fprint(w, `{"id":7}`)
+		case "/repos/softleader/s2i/releases/7/assets":
+			fmt.Fprint(w, `[{"id":1,"name":"s2i-linux.tgz"},{"id":2,"name":"s2i-darwin.tgz"},{"id":3,"name":"broken.tgz"}]`)
+		case "/repos/softleader/s2i/releases/assets/1":
+			fmt.Fprint(w, "linux")
+		case "/repos/softleader/s2i/releases/assets/2":
+			http.Redirect(w, r, server.URL+"/storage/2", http.StatusFound)
+		case "/storage/2":
+			if r.Header.Get("Authorization") != "" {
+				http.Error(w, "only one auth mechanism allowed", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, "darwin")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	SetClientOptions(&ClientOptions{BaseURL: server.URL + "/"})
+
+	var redirected []string
+	hc := &http.Client{Transport: &oauth2.Transport{
+		Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "secret"}),
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/storage/2" {
+				redirected = append(redirected, req.URL.Path)
+			}
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}}
+	c, err := NewClientWithHTTPClient(logrus.StandardLogger(), hc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "s2i-assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	results, err := c.DownloadReleaseAssets(context.Background(), "softleader", "s2i", "v1.2.3", dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("should have 3 results, but got %d", len(results))
+	}
+	for _, expected := range []struct{ name, content string }{{"s2i-linux.tgz", "linux"}, {"s2i-darwin.tgz", "darwin"}} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, expected.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected.content {
+			t.Errorf("content of %s should be %q, but got %q", expected.name, expected.content, b)
+		}
+	}
+	if len(redirected) != 1 {
+		t.Errorf("redirect should be followed through the transport of injected http client, but got %v", redirected)
+	}
+	if results[2].Err == nil {
+		t.Errorf("downloading broken asset should fail")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.tgz")); !os.IsNotExist(err) {
+		t.Errorf("failed asset should not be written, but got %v", err)
+	}
+}
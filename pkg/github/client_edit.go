@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// PublishRelease 發佈 tag 的 draft release
+func PublishRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) (*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.PublishRelease(ctx, owner, repo, tag)
+}
+
+// PublishRelease 同 package function PublishRelease
+func (c *Client) PublishRelease(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	release, err := publishRelease(ctx, c.log, c.repos, owner, repo, tag)
+	return release, wrapError(err)
+}
+
+func publishRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) (*Release, error) {
+	draft, err := findDraftRelease(ctx, log, repos, owner, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("publishing draft release %s by release-id %d", tag, draft.GetID())
+	release, _, err := repos.EditRelease(ctx, owner, repo, draft.GetID(), &github.RepositoryRelease{
+		Draft: github.Bool(false),
+	})
+	if err != nil {
+		return nil, err
+	}
+	success(log, "Successfully published release: %s", release.GetHTMLURL())
+	return newRelease(release), nil
+}
+
+// UpdateRelease 修改 tag 的 release 名稱及內容, 空白的欄位會保持不變
+func UpdateRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag, name, body string) (*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.UpdateRelease(ctx, owner, repo, tag, name, body)
+}
+
+// UpdateRelease 同 package function UpdateRelease
+func (c *Client) UpdateRelease(ctx context.Context, owner, repo, tag, name, body string) (*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	release, err := updateRelease(ctx, c.log, c.repos, owner, repo, tag, name, body)
+	return release, wrapError(err)
+}
+
+func updateRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag, name, body string) (*Release, error) {
+	rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("release %s not found in %s/%s", tag, owner, repo)}
+		}
+		return nil, err
+	}
+	edit := &github.RepositoryRelease{}
+	if name != "" {
+		edit.Name = github.String(name)
+	}
+	if body != "" {
+		edit.Body = github.String(body)
+	}
+	log.Debugf("updating release %s by release-id %d", tag, rr.GetID())
+	release, _, err := repos.EditRelease(ctx, owner, repo, rr.GetID(), edit)
+	if err != nil {
+		return nil, err
+	}
+	success(log, "Successfully updated release: %s", release.GetHTMLURL())
+	return newRelease(release), nil
+}
+
+// AppendToReleaseBody 在 tag 的 release 內容之後加上 content, 原有的內容不為空時以空行分隔, 不會覆蓋之前產生或手寫的內容
+func AppendToReleaseBody(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag, content string) (*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.AppendToReleaseBody(ctx, owner, repo, tag, content)
+}
+
+// AppendToReleaseBody 同 package function AppendToReleaseBody
+func (c *Client) AppendToReleaseBody(ctx context.Context, owner, repo, tag, content string) (*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	release, err := appendToReleaseBody(ctx, c.log, c.repos, owner, repo, tag, content)
+	return release, wrapError(err)
+}
+
+func appendToReleaseBody(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag, content string) (*Release, error) {
+	rr, err := getReleaseByTag(ctx, log, repos, owner, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	body := appendBody(rr.GetBody(), content)
+	log.Debugf("appending to body of release %s by release-id %d", tag, rr.GetID())
+	release, _, err := repos.EditRelease(ctx, owner, repo, rr.GetID(), &github.RepositoryRelease{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	success(log, "Successfully appended to release: %s", release.GetHTMLURL())
+	return newRelease(release), nil
+}
+
+// appendBody 以空行分隔 body 及 content, 避免 markdown 的段落黏在一起
+func appendBody(body, content string) string {
+	body = strings.TrimRight(body, "\r\n")
+	if body == "" {
+		return content
+	}
+	return body + "\n\n" + content
+}
+
+// PromoteRelease 將 tag 的 pre-release 轉為正式的 release, tag 不是 pre-release 時回傳錯誤
+func PromoteRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) (*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.PromoteRelease(ctx, owner, repo, tag)
+}
+
+// PromoteRelease 同 package function PromoteRelease
+func (c *Client) PromoteRelease(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	release, err := promoteRelease(ctx, c.log, c.repos, owner, repo, tag)
+	return release, wrapError(err)
+}
+
+func promoteRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) (*Release, error) {
+	rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("release %s not found in %s/%s", tag, owner, repo)}
+		}
+		return nil, err
+	}
+	if !rr.GetPrerelease() {
+		return nil, invalid(fmt.Errorf("release %s is not a pre-release", tag))
+	}
+	log.Debugf("promoting pre-release %s by release-id %d", tag, rr.GetID())
+	release, _, err := repos.EditRelease(ctx, owner, repo, rr.GetID(), &github.RepositoryRelease{
+		Prerelease: github.Bool(false),
+	})
+	if err != nil {
+		return nil, err
+	}
+	success(log, "Successfully promoted release: %s", release.GetHTMLURL())
+	return newRelease(release), nil
+}
+
+// findDraftRelease 找出 tag 的 draft release, 因 GetReleaseByTag 不會回傳 draft, 所以需要列出所有 release 來找
+func findDraftRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) (*github.RepositoryRelease, error) {
+	releases, err := listReleases(ctx, log, repos, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, rr := range releases {
+		if rr.GetDraft() && rr.GetTagName() == tag {
+			return rr, nil
+		}
+	}
+	return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("draft release %s not found in %s/%s", tag, owner, repo)}
+}
@@ -2,25 +2,38 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"github.com/blang/semver"
 	"github.com/google/go-github/v28/github"
 	"github.com/sirupsen/logrus"
+	"strings"
 )
 
 // ListReleaseByMatcher 依照指定 matcher 列出符合的 release 資訊
-func ListReleaseByMatcher(log *logrus.Logger, token, owner, repo string, matcher TagMatcher) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+func ListReleaseByMatcher(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, matcher TagMatcher) error {
+	c, err := NewClient(ctx, log, token)
 	if err != nil {
 		return err
 	}
+	return c.ListReleaseByMatcher(ctx, owner, repo, matcher)
+}
+
+// ListReleaseByMatcher 同 package function ListReleaseByMatcher
+func (c *Client) ListReleaseByMatcher(ctx context.Context, owner, repo string, matcher TagMatcher) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(listReleaseByMatcher(ctx, c.log, c.repos, owner, repo, matcher))
+}
 
+func listReleaseByMatcher(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, matcher TagMatcher) error {
 	opt := &github.ListOptions{
 		Page:    1,
 		PerPage: 100,
 	}
 	for {
 		log.Debugf("fetching page %v of tags", opt.Page)
-		releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opt)
+		releases, resp, err := repos.ListReleases(ctx, owner, repo, opt)
 		if err != nil {
 			return err
 		}
@@ -42,15 +55,25 @@ func ListReleaseByMatcher(log *logrus.Logger, token, owner, repo string, matcher
 }
 
 // ListRelease 依照 release 名稱列出符合相關資訊
-func ListRelease(log *logrus.Logger, token, owner, repo string, tags []string) error {
-	ctx := context.Background()
-	client, err := newTokenClient(ctx, token)
+func ListRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo string, tags []string) error {
+	c, err := NewClient(ctx, log, token)
 	if err != nil {
 		return err
 	}
+	return c.ListRelease(ctx, owner, repo, tags)
+}
 
+// ListRelease 同 package function ListRelease
+func (c *Client) ListRelease(ctx context.Context, owner, repo string, tags []string) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(listRelease(ctx, c.log, c.repos, owner, repo, tags))
+}
+
+func listRelease(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string, tags []string) error {
 	for _, tag := range tags {
-		rr, _, err := client.Repositories.GetReleaseByTag(ctx, owner, repo, tag)
+		rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
 		if err != nil {
 			githubErr, ok := err.(*github.ErrorResponse)
 			if !ok {
@@ -65,3 +88,184 @@ func ListRelease(log *logrus.Logger, token, owner, repo string, tags []string) e
 	}
 	return nil
 }
+
+// GetLatestRelease 取得 repo 的 latest release 資訊, repo 尚未有任何 release 時回傳錯誤
+func GetLatestRelease(ctx context.Context, log logrus.FieldLogger, token, owner, repo string) (*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetLatestRelease(ctx, owner, repo)
+}
+
+// GetLatestRelease 同 package function GetLatestRelease
+func (c *Client) GetLatestRelease(ctx context.Context, owner, repo string) (*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	rr, err := getLatestRelease(ctx, c.log, c.repos, owner, repo)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if rr == nil {
+		return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("%s/%s has no release yet", owner, repo)}
+	}
+	return newRelease(rr), nil
+}
+
+// GetReleaseByTag 取得 tag 的 release 資訊, 如: 確認 release 是否仍為 draft 或 pre-release
+// tag 沒有 release 時回傳 KindNotFound 的錯誤, 可透過 KindOf 判斷
+func GetReleaseByTag(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) (*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetReleaseByTag(ctx, owner, repo, tag)
+}
+
+// GetReleaseByTag 同 package function GetReleaseByTag
+func (c *Client) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	rr, err := getReleaseByTag(ctx, c.log, c.repos, owner, repo, tag)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return newRelease(rr), nil
+}
+
+func getReleaseByTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) (*github.RepositoryRelease, error) {
+	log.Debugf("fetching release of tag '%s'", tag)
+	rr, _, err := repos.GetReleaseByTag(ctx, owner, repo, tag)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("release %s not found in %s/%s", tag, owner, repo)}
+		}
+		return nil, err
+	}
+	return rr, nil
+}
+
+// GetReleaseURL 回傳 tag 的 release 網頁位置, 如: 發送通知時附上 release 的連結, tag 沒有 release 時回傳錯誤
+func GetReleaseURL(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) (string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.GetReleaseURL(ctx, owner, repo, tag)
+}
+
+// GetReleaseURL 同 package function GetReleaseURL
+func (c *Client) GetReleaseURL(ctx context.Context, owner, repo, tag string) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	u, err := getReleaseURL(ctx, c.log, c.repos, owner, repo, tag)
+	return u, wrapError(err)
+}
+
+func getReleaseURL(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string) (string, error) {
+	rr, err := getReleaseByTag(ctx, log, repos, owner, repo, tag)
+	if err != nil {
+		return "", err
+	}
+	return rr.GetHTMLURL(), nil
+}
+
+// ListReleases 列出 repo 所有的 release, 包含 draft 及 pre-release
+func ListReleases(ctx context.Context, log logrus.FieldLogger, token, owner, repo string) ([]*Release, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListReleases(ctx, owner, repo)
+}
+
+// ListReleases 同 package function ListReleases
+func (c *Client) ListReleases(ctx context.Context, owner, repo string) ([]*Release, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	rrs, err := listReleases(ctx, c.log, c.repos, owner, repo)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var releases []*Release
+	for _, rr := range rrs {
+		releases = append(releases, newRelease(rr))
+	}
+	return releases, nil
+}
+
+// listReleases 分頁取得 repo 所有的 release
+func listReleases(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) ([]*github.RepositoryRelease, error) {
+	var releases []*github.RepositoryRelease
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+	for {
+		log.Debugf("fetching page %v of releases", opt.Page)
+		page, resp, err := repos.ListReleases(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	return releases, nil
+}
+
+// FindPreviousReleaseTag 找出 semver 小於 tag 中最大的 release tag, 適合做為產生 changelog 時 CompareCommits 的 base
+// fromTags 為 true 時改由所有的 tag 中尋找, 非 semver 的 tag 會被略過, tag 已是最早的版本時回傳空字串
+func FindPreviousReleaseTag(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string, fromTags bool) (string, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return "", err
+	}
+	return c.FindPreviousReleaseTag(ctx, owner, repo, tag, fromTags)
+}
+
+// FindPreviousReleaseTag 同 package function FindPreviousReleaseTag
+func (c *Client) FindPreviousReleaseTag(ctx context.Context, owner, repo, tag string, fromTags bool) (string, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return "", err
+	}
+	previous, err := findPreviousReleaseTag(ctx, c.log, c.repos, owner, repo, tag, fromTags)
+	return previous, wrapError(err)
+}
+
+func findPreviousReleaseTag(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo, tag string, fromTags bool) (string, error) {
+	if _, err := semver.Parse(strings.TrimPrefix(tag, "v")); err != nil {
+		return "", invalid(fmt.Errorf("invalid version %q: %s", tag, err))
+	}
+	tags, err := listVersionTags(ctx, log, repos, owner, repo, fromTags)
+	if err != nil {
+		return "", err
+	}
+	previous := previousOf(log, tags, tag)
+	log.Debugf("found previous tag %q of %s", previous, tag)
+	return previous, nil
+}
+
+// previousOf 回傳 tags 中 semver 小於 tag 的最大者, 沒有時回傳空字串
+func previousOf(log logrus.FieldLogger, tags []string, tag string) (previous string) {
+	target := mustParse(tag)
+	var max semver.Version
+	for _, t := range tags {
+		sv, err := semver.Parse(strings.TrimPrefix(t, "v"))
+		if err != nil {
+			log.Debugf("skipping non-semver tag %s", t)
+			continue
+		}
+		if sv.LT(target) && (previous == "" || sv.GT(max)) {
+			previous, max = t, sv
+		}
+	}
+	return
+}
@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MilestoneOpen 尚未關閉的 milestone
+	MilestoneOpen = "open"
+	// MilestoneClosed 已關閉的 milestone
+	MilestoneClosed = "closed"
+	// MilestoneAll 所有的 milestone
+	MilestoneAll = "all"
+)
+
+// Milestone wrap GitHub Milestone
+type Milestone struct {
+	Number       int
+	Title        string
+	State        string
+	OpenIssues   int
+	ClosedIssues int
+	HTMLURL      string
+}
+
+func newMilestone(m *github.Milestone) *Milestone {
+	return &Milestone{
+		Number:       m.GetNumber(),
+		Title:        m.GetTitle(),
+		State:        m.GetState(),
+		OpenIssues:   m.GetOpenIssues(),
+		ClosedIssues: m.GetClosedIssues(),
+		HTMLURL:      m.GetHTMLURL(),
+	}
+}
+
+// ListMilestones 列出 repo 中 state 的 milestone, state 可以是 MilestoneOpen, MilestoneClosed 或 MilestoneAll, 預設為 MilestoneAll
+func ListMilestones(ctx context.Context, log logrus.FieldLogger, token, owner, repo, state string) ([]*Milestone, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListMilestones(ctx, owner, repo, state)
+}
+
+// ListMilestones 同 package function ListMilestones
+func (c *Client) ListMilestones(ctx context.Context, owner, repo, state string) ([]*Milestone, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	ms, err := listMilestones(ctx, c.log, c.issues, owner, repo, state)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var milestones []*Milestone
+	for _, m := range ms {
+		milestones = append(milestones, newMilestone(m))
+	}
+	return milestones, nil
+}
+
+// FindMilestone 依照 title 找出 milestone (不論是否已關閉), 可用來在 release 說明中加上 milestone 的進度
+// 找不到時回傳 KindNotFound 的錯誤
+func FindMilestone(ctx context.Context, log logrus.FieldLogger, token, owner, repo, title string) (*Milestone, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.FindMilestone(ctx, owner, repo, title)
+}
+
+// FindMilestone 同 package function FindMilestone
+func (c *Client) FindMilestone(ctx context.Context, owner, repo, title string) (*Milestone, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	m, err := findMilestone(ctx, c.log, c.issues, owner, repo, title)
+	return m, wrapError(err)
+}
+
+func findMilestone(ctx context.Context, log logrus.FieldLogger, issues issuesService, owner, repo, title string) (*Milestone, error) {
+	ms, err := listMilestones(ctx, log, issues, owner, repo, MilestoneAll)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		if m.GetTitle() == title {
+			log.Debugf("found milestone #%d %q: %d closed, %d open", m.GetNumber(), title, m.GetClosedIssues(), m.GetOpenIssues())
+			return newMilestone(m), nil
+		}
+	}
+	return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("milestone %q not found in %s/%s, found %d milestone(s) of other titles", title, owner, repo, len(ms))}
+}
+
+func listMilestones(ctx context.Context, log logrus.FieldLogger, issues issuesService, owner, repo, state string) ([]*github.Milestone, error) {
+	switch state {
+	case "":
+		state = MilestoneAll
+	case MilestoneOpen, MilestoneClosed, MilestoneAll:
+	default:
+		return nil, invalid(fmt.Errorf("unsupported milestone state: %q", state))
+	}
+	var milestones []*github.Milestone
+	opt := &github.MilestoneListOptions{
+		State:       state,
+		ListOptions: github.ListOptions{Page: 1, PerPage: 100},
+	}
+	for {
+		log.Debugf("fetching page %v of %s milestones of %s/%s", opt.Page, state, owner, repo)
+		page, resp, err := issues.ListMilestones(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		log.Debugf("moving to the next page: %v/%v", resp.NextPage, resp.LastPage)
+		opt.Page = resp.NextPage
+	}
+	return milestones, nil
+}
@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+)
+
+// Repository wrap GitHub Repository
+type Repository struct {
+	Owner         string
+	Name          string
+	FullName      string
+	DefaultBranch string
+	Private       bool
+	Archived      bool
+	// Permissions token 的使用者對 repo 的權限, key 為 admin, push 及 pull
+	Permissions map[string]bool
+}
+
+// CanPush 判斷 token 的使用者是否有 push 的權限, 建立 release 及 tag 都需要此權限
+func (r *Repository) CanPush() bool {
+	return r.Permissions["push"] || r.Permissions["admin"]
+}
+
+// PermissionLevel 回傳 token 的使用者對 repo 最高的權限, 依序為 admin, maintain, write, triage, read 及 none
+func (r *Repository) PermissionLevel() string {
+	switch {
+	case r.Permissions["admin"]:
+		return "admin"
+	case r.Permissions["maintain"]:
+		return "maintain"
+	case r.Permissions["push"]:
+		return "write"
+	case r.Permissions["triage"]:
+		return "triage"
+	case r.Permissions["pull"]:
+		return "read"
+	default:
+		return "none"
+	}
+}
+
+func newRepository(r *github.Repository) *Repository {
+	repository := &Repository{
+		Owner:         r.GetOwner().GetLogin(),
+		Name:          r.GetName(),
+		FullName:      r.GetFullName(),
+		DefaultBranch: r.GetDefaultBranch(),
+		Private:       r.GetPrivate(),
+		Archived:      r.GetArchived(),
+		Permissions:   make(map[string]bool),
+	}
+	if r.Permissions != nil {
+		for k, v := range *r.Permissions {
+			repository.Permissions[k] = v
+		}
+	}
+	return repository
+}
+
+// GetRepository 取得 repo 的基本資訊, 可在 release 前確認 repo 沒有被 archived 且有 push 的權限
+func GetRepository(ctx context.Context, log logrus.FieldLogger, token, owner, repo string) (*Repository, error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetRepository(ctx, owner, repo)
+}
+
+// GetRepository 同 package function GetRepository
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return nil, err
+	}
+	r, err := getRepository(ctx, c.log, c.repos, owner, repo)
+	return r, wrapError(err)
+}
+
+func getRepository(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) (*Repository, error) {
+	log.Debugf("fetching repository %s/%s", owner, repo)
+	r, _, err := repos.Get(ctx, owner, repo)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, &Error{Kind: KindNotFound, Err: fmt.Errorf("repository %s/%s not found", owner, repo)}
+		}
+		return nil, err
+	}
+	return newRepository(r), nil
+}
+
+// ensureNotArchived 確認 repo 沒有被 archived, archived 的 repo 是唯讀的, 建立 release 只會得到難以理解的錯誤
+func ensureNotArchived(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) error {
+	r, err := getRepository(ctx, log, repos, owner, repo)
+	if err != nil {
+		return err
+	}
+	if r.Archived {
+		return invalid(fmt.Errorf("%s/%s is archived and read-only, unarchive it before releasing", owner, repo))
+	}
+	return nil
+}
+
+// CheckPermissions 確認 token 的使用者對 repo 有建立 release 所需的 write 以上的權限, 適合在 release 前先行檢查
+// 權限不足時回傳 KindUnauthorized 的錯誤, 避免到了 CreateRelease 才得到難以理解的 403
+func CheckPermissions(ctx context.Context, log logrus.FieldLogger, token, owner, repo string) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.CheckPermissions(ctx, owner, repo)
+}
+
+// CheckPermissions 同 package function CheckPermissions
+func (c *Client) CheckPermissions(ctx context.Context, owner, repo string) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	return wrapError(checkPermissions(ctx, c.log, c.repos, owner, repo))
+}
+
+func checkPermissions(ctx context.Context, log logrus.FieldLogger, repos repositoriesService, owner, repo string) error {
+	r, err := getRepository(ctx, log, repos, owner, repo)
+	if err != nil {
+		return err
+	}
+	level := r.PermissionLevel()
+	log.Debugf("permission of token to %s/%s: %s", owner, repo, level)
+	if !r.CanPush() {
+		return &Error{Kind: KindUnauthorized, Err: fmt.Errorf("you don't have write access to %s/%s (permission: %s), which is required to create releases", owner, repo, level)}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+var (
+	// 如: "Merge pull request #12 from softleader/feature"
+	mergedPullRequest = regexp.MustCompile(`^Merge pull request (#\d+) from \S+$`)
+	// 如: "Add signature upload (#12)"
+	squashedPullRequest = regexp.MustCompile(`^(.+) \((#\d+)\)$`)
+)
+
+// ReleaseSummary 建立 release 後的摘要, 方便組成 Slack 或 Teams 的通知訊息
+type ReleaseSummary struct {
+	Tag    string
+	URL    string
+	Author string
+	// Previous 上一個 release 的 tag, 第一次 release 時為空
+	Previous string
+	// CommitCount 上一個 release 之後的 commit 數量
+	CommitCount int
+	// PullRequests 上一個 release 之後 merged 的 PR 標題, 如: Add signature upload (#12)
+	PullRequests []string
+}
+
+// CreateReleaseWithSummary 建立 release 並回傳其摘要, 參數同 CreateRelease
+// 建立 release 後取得 commits 失敗時, 仍會回傳只有 release 資訊的摘要及錯誤, DryRun 時不呼叫 GitHub API 因此也只有 release 資訊
+func CreateReleaseWithSummary(ctx context.Context, log logrus.FieldLogger, token, owner, repo, branch, tag string, opts *ReleaseOptions) (*ReleaseSummary, error) {
+	c, err := clientFor(ctx, log, token, opts.dryRun())
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateReleaseWithSummary(ctx, owner, repo, branch, tag, opts)
+}
+
+// CreateReleaseWithSummary 同 package function CreateReleaseWithSummary
+func (c *Client) CreateReleaseWithSummary(ctx context.Context, owner, repo, branch, tag string, opts *ReleaseOptions) (*ReleaseSummary, error) {
+	if err := validateRelease(owner, repo, branch, tag); err != nil {
+		return nil, err
+	}
+	if opts.dryRun() {
+		release, err := c.CreateRelease(ctx, owner, repo, branch, tag, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &ReleaseSummary{Tag: release.TagName, URL: release.HTMLURL, Author: release.Author.GetLogin()}, nil
+	}
+	previous, err := findPreviousReleaseTag(ctx, c.log, c.repos, owner, repo, tag, false)
+	if KindOf(err) == KindInvalid { // tag 不是 semver 時以 latest release 為準
+		previous, err = findLatestReleaseTag(ctx, c.log, c.repos, owner, repo)
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	release, err := c.CreateRelease(ctx, owner, repo, branch, tag, opts)
+	if err != nil {
+		return nil, err
+	}
+	summary := &ReleaseSummary{
+		Tag:      release.TagName,
+		URL:      release.HTMLURL,
+		Author:   release.Author.GetLogin(),
+		Previous: previous,
+	}
+	commits, err := c.releasedCommits(ctx, owner, repo, previous, branch)
+	if err != nil {
+		return summary, wrapError(fmt.Errorf("release %s created, but failed to summarize its commits: %s", tag, err))
+	}
+	summary.CommitCount = len(commits)
+	summary.PullRequests = pullRequestTitles(commits)
+	return summary, nil
+}
+
+// releasedCommits 列出 previous...ref 之間的 commits, previous 為空時列出 ref 所有的 commits
+func (c *Client) releasedCommits(ctx context.Context, owner, repo, previous, ref string) ([]*Commit, error) {
+	var rcs []*github.RepositoryCommit
+	var err error
+	if previous == "" {
+		rcs, err = listCommits(ctx, c.log, c.repos, owner, repo, ref)
+	} else {
+		rcs, err = compareCommits(ctx, c.log, c.repos, owner, repo, previous, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var commits []*Commit
+	for _, rc := range rcs {
+		commits = append(commits, newCommit(rc))
+	}
+	return commits, nil
+}
+
+// pullRequestTitles 從 merge commit 或 squash commit 的訊息中找出 PR 的標題, 如: Add signature upload (#12)
+func pullRequestTitles(commits []*Commit) []string {
+	var titles []string
+	for _, c := range commits {
+		lines := strings.Split(strings.TrimSpace(c.Message), "\n")
+		subject := strings.TrimSpace(lines[0])
+		if m := mergedPullRequest.FindStringSubmatch(subject); m != nil {
+			title := subject
+			for _, line := range lines[1:] { // merge commit 的標題在空行之後
+				if line = strings.TrimSpace(line); line != "" {
+					title = line
+					break
+				}
+			}
+			titles = append(titles, fmt.Sprintf("%s (%s)", title, m[1]))
+			continue
+		}
+		if m := squashedPullRequest.FindStringSubmatch(subject); m != nil {
+			titles = append(titles, fmt.Sprintf("%s (%s)", m[1], m[2]))
+		}
+	}
+	return titles
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"regexp"
+)
+
+var (
+	rsha = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// createAnnotatedTag 在 target (branch 或 commit sha) 上建立帶有 opts.TagMessage 的 annotated tag 及其 refs/tags
+func createAnnotatedTag(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, target, tag string, opts *ReleaseOptions) error {
+	sha, err := resolveCommit(ctx, log, git, owner, repo, target)
+	if err != nil {
+		return err
+	}
+	log.Debugf("creating annotated tag %s on %s", tag, sha)
+	t, _, err := git.CreateTag(ctx, owner, repo, &github.Tag{
+		Tag:     github.String(tag),
+		Message: github.String(opts.TagMessage),
+		Tagger:  opts.Tagger,
+		Object: &github.GitObject{
+			Type: github.String("commit"),
+			SHA:  github.String(sha),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	log.Debugf("creating refs/tags/%s to tag object %s", tag, t.GetSHA())
+	_, _, err = git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String(fmt.Sprintf("refs/tags/%s", tag)),
+		Object: &github.GitObject{SHA: t.SHA},
+	})
+	return err
+}
+
+// resolveCommit 回傳 target 的 commit sha, target 本身就是 sha 時直接回傳, 否則視為 branch
+func resolveCommit(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, target string) (string, error) {
+	if rsha.MatchString(target) {
+		return target, nil
+	}
+	log.Debugf("fetching refs/heads/%s of %s/%s", target, owner, repo)
+	ref, _, err := git.GetRef(ctx, owner, repo, fmt.Sprintf("heads/%s", target))
+	if err != nil {
+		return "", err
+	}
+	if ref.GetRef() != fmt.Sprintf("refs/heads/%s", target) {
+		return "", &Error{Kind: KindNotFound, Err: fmt.Errorf("branch %s not found in %s/%s", target, owner, repo)}
+	}
+	return ref.GetObject().GetSHA(), nil
+}
+
+// VerifyTagCommit 確認 tag 指向的 commit 是否有通過 GitHub 驗證的簽章 (如: GPG), 未通過時 reason 為 GitHub 回傳的原因, 如: unsigned
+func VerifyTagCommit(ctx context.Context, log logrus.FieldLogger, token, owner, repo, tag string) (verified bool, reason string, err error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return false, "", err
+	}
+	return c.VerifyTagCommit(ctx, owner, repo, tag)
+}
+
+// VerifyTagCommit 同 package function VerifyTagCommit
+func (c *Client) VerifyTagCommit(ctx context.Context, owner, repo, tag string) (verified bool, reason string, err error) {
+	if err := validateRepo(owner, repo); err != nil {
+		return false, "", err
+	}
+	sha, err := resolveTagCommit(ctx, c.log, c.git, owner, repo, tag)
+	if err != nil {
+		return false, "", wrapError(err)
+	}
+	verified, reason, err = verifyCommit(ctx, c.log, c.git, owner, repo, sha)
+	return verified, reason, wrapError(err)
+}
+
+// resolveTagCommit 回傳 tag 指向的 commit sha, annotated tag 會再透過 tag object 找到 commit
+func resolveTagCommit(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, tag string) (string, error) {
+	log.Debugf("fetching refs/tags/%s of %s/%s", tag, owner, repo)
+	ref, _, err := git.GetRef(ctx, owner, repo, fmt.Sprintf("tags/%s", tag))
+	if err != nil && !isNotFound(err) {
+		return "", err
+	}
+	if err != nil || ref.GetRef() != fmt.Sprintf("refs/tags/%s", tag) {
+		return "", &Error{Kind: KindNotFound, Err: fmt.Errorf("tag %s not found in %s/%s", tag, owner, repo)}
+	}
+	obj := ref.GetObject()
+	if obj.GetType() == "tag" {
+		log.Debugf("fetching tag object %s of %s", obj.GetSHA(), tag)
+		t, _, err := git.GetTag(ctx, owner, repo, obj.GetSHA())
+		if err != nil {
+			return "", err
+		}
+		obj = t.GetObject()
+	}
+	return obj.GetSHA(), nil
+}
+
+// verifyCommit 回傳 commit 的簽章是否通過 GitHub 驗證及其原因
+func verifyCommit(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, sha string) (bool, string, error) {
+	log.Debugf("fetching verification of commit %s", sha)
+	c, _, err := git.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		return false, "", err
+	}
+	v := c.GetVerification()
+	return v.GetVerified(), v.GetReason(), nil
+}
+
+// ensureSigned 確認 release 的 commit 有通過簽章驗證, tag 已存在時檢查 tag 指向的 commit, 否則檢查 branch 的 commit
+func ensureSigned(ctx context.Context, log logrus.FieldLogger, git gitService, owner, repo, branch, tag string, exists bool) error {
+	var sha string
+	var err error
+	if exists {
+		sha, err = resolveTagCommit(ctx, log, git, owner, repo, tag)
+	} else {
+		sha, err = resolveCommit(ctx, log, git, owner, repo, branch)
+	}
+	if err != nil {
+		return err
+	}
+	verified, reason, err := verifyCommit(ctx, log, git, owner, repo, sha)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return invalid(fmt.Errorf("commit %s of %s is not signed: %s", sha, tag, reason))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"github.com/google/go-github/v28/github"
+	"net"
+	"net/http"
+)
+
+// ErrorKind 錯誤的種類, 讓呼叫端可以依照種類顯示訊息或決定 exit code
+type ErrorKind int
+
+const (
+	// KindUnknown 無法歸類的錯誤
+	KindUnknown ErrorKind = iota
+	// KindInvalid 傳入的參數不正確
+	KindInvalid
+	// KindUnauthorized 找不到 token 或 token 沒有權限
+	KindUnauthorized
+	// KindNotFound repo, release 或 tag 不存在
+	KindNotFound
+	// KindRateLimited 超過 GitHub 的 rate limit
+	KindRateLimited
+	// KindTimeout 等待 GitHub 回應超過時限
+	KindTimeout
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindInvalid:
+		return "invalid"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindNotFound:
+		return "not found"
+	case KindRateLimited:
+		return "rate limited"
+	case KindTimeout:
+		return "timeout"
+	default:
+		return "unknown"
+	}
+}
+
+// Error 帶有種類的錯誤, Err 為原始的錯誤
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// KindOf 回傳錯誤的種類, 不是 *Error 的錯誤為 KindUnknown
+func KindOf(err error) ErrorKind {
+	if e, ok := err.(*Error); ok {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// wrapError 依照 GitHub 的回應將錯誤包裝成 *Error, 已包裝過或 nil 則原封不動回傳
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	kind := KindUnknown
+	switch e := err.(type) {
+	case *github.RateLimitError, *github.AbuseRateLimitError:
+		kind = KindRateLimited
+	case *github.ErrorResponse:
+		if e.Response != nil {
+			switch e.Response.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				kind = KindUnauthorized
+			case http.StatusNotFound:
+				kind = KindNotFound
+			}
+		}
+	case net.Error:
+		if e.Timeout() {
+			kind = KindTimeout
+		}
+	default:
+		switch err {
+		case ErrTokenNotFound:
+			kind = KindUnauthorized
+		case context.DeadlineExceeded:
+			kind = KindTimeout
+		}
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// invalid 建立 KindInvalid 的錯誤
+func invalid(err error) error {
+	return &Error{Kind: KindInvalid, Err: err}
+}
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-github/v28/github"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestWrapError(t *testing.T) {
+	if err := wrapError(nil); err != nil {
+		t.Errorf("wrapping nil should be nil, but got %v", err)
+	}
+	response := func(code int) *http.Response {
+		return &http.Response{StatusCode: code}
+	}
+	for _, c := range []struct {
+		name     string
+		err      error
+		expected ErrorKind
+	}{
+		{"plain", errors.New("boom"), KindUnknown},
+		{"token", ErrTokenNotFound, KindUnauthorized},
+		{"401", &github.ErrorResponse{Response: response(http.StatusUnauthorized)}, KindUnauthorized},
+		{"404", notFound(), KindNotFound},
+		{"rate limit", &github.RateLimitError{Response: response(http.StatusForbidden)}, KindRateLimited},
+		{"abuse rate limit", &github.AbuseRateLimitError{Response: response(http.StatusForbidden)}, KindRateLimited},
+		{"invalid", invalid(errors.New("owner is required")), KindInvalid},
+		{"deadline", context.DeadlineExceeded, KindTimeout},
+		{"http timeout", &url.Error{Op: "Get", URL: "https://api.github.com", Err: timeoutError{}}, KindTimeout},
+	} {
+		if kind := KindOf(wrapError(c.err)); kind != c.expected {
+			t.Errorf("kind of %s error should be %v, but got %v", c.name, c.expected, kind)
+		}
+	}
+}
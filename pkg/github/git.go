@@ -0,0 +1,69 @@
+package github
+
+import (
+	"github.com/sirupsen/logrus"
+	"os/exec"
+	"strings"
+)
+
+// gitCLI 是否一律使用 git 指令解析 remote 及 HEAD, 預設只在自行解析 .git 失敗時才使用
+// 沒有使用 go-git 是為了不增加相依的套件數量, git 指令本身就能完整處理 include, includeIf, packed-refs 等設定,
+// 代價是執行時 PATH 中必須有 git, 找不到 git 時視同解析失敗, 回傳空的結果
+var gitCLI bool
+
+// SetGitCLI 設定是否一律使用 git 指令解析 remote 及 HEAD
+// git 指令能正確處理 include, includeIf 等自行解析 .git 時無法處理的設定, 但 PATH 中必須有 git
+func SetGitCLI(enabled bool) {
+	gitCLI = enabled
+}
+
+// runGit 在 pwd 中執行 git 指令, 回傳去除頭尾空白的 stdout, PATH 中沒有 git 時回傳 *exec.Error
+func runGit(log logrus.FieldLogger, pwd string, args ...string) (string, error) {
+	log.Debugf("running: git %s", strings.Join(args, " "))
+	cmd := exec.Command("git", args...)
+	cmd.Dir = pwd
+	b, err := cmd.Output()
+	if err != nil {
+		log.Debugf("git %s failed: %s", args[0], err)
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// findRemoteByGit 透過 git config 找出指定 remote 的資訊, 若指定的 remote 不存在, 則使用第一個找到的 remote
+func findRemoteByGit(log logrus.FieldLogger, pwd, name string) *GitRemote {
+	out, err := runGit(log, pwd, "config", "--get-regexp", `^remote\..*\.url$`)
+	if err != nil {
+		return &GitRemote{}
+	}
+	var remotes []remote
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(fields[0], "remote."), ".url")
+		remotes = append(remotes, remote{name: key, url: fields[1]})
+	}
+	return selectRemote(log, remotes, name)
+}
+
+// headByGit 透過 git 指令回傳當前的 branch, 規則同 Head
+func headByGit(log logrus.FieldLogger, pwd string) (head string, detached bool) {
+	if branch, err := runGit(log, pwd, "symbolic-ref", "--short", "-q", "HEAD"); err == nil && branch != "" {
+		return branch, false
+	}
+	sha, err := runGit(log, pwd, "rev-parse", "HEAD")
+	if err != nil || sha == "" {
+		return "", false
+	}
+	log.Debugf("HEAD is detached at %s", sha)
+	out, err := runGit(log, pwd, "for-each-ref", "--points-at", sha, "--format=%(refname:short)", "refs/heads")
+	if err == nil && out != "" {
+		if branches := strings.Split(out, "\n"); len(branches) == 1 {
+			log.Debugf("resolved detached HEAD to branch %s", branches[0])
+			return branches[0], false
+		}
+	}
+	return sha, true
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitCLI(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	tmp, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	log := logrus.StandardLogger()
+	git := func(args ...string) string {
+		args = append([]string{"-c", "user.name=s2i", "-c", "user.email=s2i@softleader.com.tw"}, args...)
+		out, err := runGit(log, tmp, args...)
+		if err != nil {
+			t.Fatalf("git %v: %s", args, err)
+		}
+		return out
+	}
+	git("init", "-q")
+	git("checkout", "-q", "-b", "master")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	// remote 定義在 include 的檔案中, 自行解析 .git/config 時找不到
+	included := filepath.Join(tmp, "remotes.gitconfig")
+	ioutil.WriteFile(included, []byte("[remote \"origin\"]\n\turl = git@github.com:softleader/s2i.git\n"), 0644)
+	git("config", "include.path", included)
+
+	if r := FindGitRemote(log, tmp, "origin"); r.Owner != "softleader" || r.Repo != "s2i" {
+		t.Errorf("remote should be found in the included config, but got %+v", r)
+	}
+	if head, detached := headByGit(log, tmp); head != "master" || detached {
+		t.Errorf("head should be master, but got %q (detached: %v)", head, detached)
+	}
+
+	sha := git("rev-parse", "HEAD")
+	git("checkout", "-q", sha)
+	if head, detached := headByGit(log, tmp); head != "master" || detached {
+		t.Errorf("detached head should be resolved to master, but got %q (detached: %v)", head, detached)
+	}
+	git("branch", "develop")
+	if head, detached := headByGit(log, tmp); head != sha || !detached {
+		t.Errorf("head should stay detached when more than one branch matches, but got %q (detached: %v)", head, detached)
+	}
+}
@@ -0,0 +1,22 @@
+package github
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// quiet 是否不輸出成功的訊息, 讓 script 只需處理自己要的資料
+var quiet bool
+
+// SetQuiet 設定是否不輸出成功的訊息, 開啟後成功的訊息改以 debug level 輸出
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// success 輸出成功的訊息, quiet 時改以 debug level 輸出
+func success(log logrus.FieldLogger, format string, args ...interface{}) {
+	if quiet {
+		log.Debugf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
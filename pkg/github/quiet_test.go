@@ -0,0 +1,26 @@
+package github
+
+import (
+	"bytes"
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestSuccess(t *testing.T) {
+	defer SetQuiet(false)
+	log := logrus.New()
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+
+	success(log, "Successfully created release: %s", "v1.0.0")
+	if b.Len() == 0 {
+		t.Error("success message should be printed")
+	}
+
+	b.Reset()
+	SetQuiet(true)
+	success(log, "Successfully created release: %s", "v1.0.0")
+	if b.Len() != 0 {
+		t.Errorf("success message should be suppressed in quiet mode, but got %q", b.String())
+	}
+}
@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// RateLimit 回傳 token 目前 core rate limit 剩餘的次數及重置的時間
+func RateLimit(ctx context.Context, log logrus.FieldLogger, token string) (remaining int, reset time.Time, err error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return c.RateLimit(ctx)
+}
+
+// RateLimit 同 package function RateLimit
+func (c *Client) RateLimit(ctx context.Context) (remaining int, reset time.Time, err error) {
+	c.log.Debugf("fetching rate limits")
+	limits, _, err := c.limits.RateLimits(ctx)
+	if err != nil {
+		return 0, time.Time{}, wrapError(err)
+	}
+	core := limits.GetCore()
+	if core == nil {
+		return 0, time.Time{}, errors.New("no core rate limit in response")
+	}
+	c.log.Debugf("core rate limit: %d/%d, reset at %s", core.Remaining, core.Limit, core.Reset)
+	return core.Remaining, core.Reset.Time, nil
+}
+
+// warnRateLimit 當 response 中剩餘的 rate limit 低於 threshold (ClientOptions.RateLimitWarning) 時輸出警告
+func warnRateLimit(log logrus.FieldLogger, threshold int, resp *github.Response) {
+	if threshold <= 0 || resp == nil || resp.Rate.Limit == 0 {
+		return
+	}
+	if resp.Rate.Remaining < threshold {
+		log.Warnf("GitHub rate limit is running low: %d/%d remaining, reset at %s", resp.Rate.Remaining, resp.Rate.Limit, resp.Rate.Reset)
+	}
+}
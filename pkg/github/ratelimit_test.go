@@ -0,0 +1,57 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockRateLimits 回傳固定的 rate limits
+type mockRateLimits struct {
+	limits *github.RateLimits
+	err    error
+}
+
+func (m *mockRateLimits) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	return m.limits, &github.Response{}, m.err
+}
+
+func TestRateLimit(t *testing.T) {
+	reset := time.Unix(1570000000, 0)
+	c := &Client{log: logrus.StandardLogger(), limits: &mockRateLimits{limits: &github.RateLimits{
+		Core: &github.Rate{Limit: 5000, Remaining: 4999, Reset: github.Timestamp{Time: reset}},
+	}}}
+	remaining, got, err := c.RateLimit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 4999 || !got.Equal(reset) {
+		t.Errorf("rate limit should be 4999 reset at %s, but got %d reset at %s", reset, remaining, got)
+	}
+
+	c.limits = &mockRateLimits{err: &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}, Message: "Bad credentials"}}
+	if _, _, err := c.RateLimit(context.Background()); KindOf(err) != KindUnauthorized {
+		t.Errorf("bad credentials should be unauthorized, but got %v", err)
+	}
+}
+
+func TestWarnRateLimit(t *testing.T) {
+	log := logrus.New()
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+	resp := &github.Response{Rate: github.Rate{Limit: 5000, Remaining: 99}}
+
+	warnRateLimit(log, 0, resp)
+	if b.Len() != 0 {
+		t.Errorf("should not warn without threshold, but got %q", b.String())
+	}
+	warnRateLimit(log, 100, resp)
+	if !strings.Contains(b.String(), "99/5000") {
+		t.Errorf("should warn the remaining rate limit, but got %q", b.String())
+	}
+}
@@ -4,26 +4,35 @@ import "github.com/google/go-github/v28/github"
 
 // Release wrap GitHub Repository Release
 type Release struct {
+	ID              int64
 	TagName         string
 	TargetCommitish string
 	Name            string
+	Body            string
 	Draft           bool
 	Prerelease      bool
 
 	PublishedAt github.Timestamp
 	HTMLURL     string
+	UploadURL   string
 	Author      *github.User
+
+	// CommentErr 設定 CommentOnCommit 時在 commit 上留言失敗的錯誤, 留言失敗不影響 release 的建立, 因此只透過此欄位通知呼叫端
+	CommentErr error
 }
 
 func newRelease(rr *github.RepositoryRelease) *Release {
 	return &Release{
+		ID:              rr.GetID(),
 		TagName:         rr.GetTagName(),
 		TargetCommitish: rr.GetTargetCommitish(),
 		Name:            rr.GetName(),
+		Body:            rr.GetBody(),
 		Draft:           rr.GetDraft(),
 		Prerelease:      rr.GetPrerelease(),
 		PublishedAt:     rr.GetPublishedAt(),
 		HTMLURL:         rr.GetHTMLURL(),
+		UploadURL:       rr.GetUploadURL(),
 		Author:          rr.GetAuthor(),
 	}
 }
@@ -0,0 +1,208 @@
+package github
+
+import (
+	"bytes"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultRetryBaseDelay 無法得知限制解除的時間時 (如: abuse rate limit 沒有提供 Retry-After), 第一次重試前的等待時間
+	defaultRetryBaseDelay = time.Minute
+	// defaultTransientDelay 遇到 5xx 或暫時性的連線錯誤時, 第一次重試前的等待時間
+	defaultTransientDelay = time.Second
+	// unknownWait 代表 GitHub 沒有告知需要等待多久
+	unknownWait time.Duration = -1
+)
+
+var (
+	// jitterRand 產生 jitter 的亂數, 每次執行的 seed 皆不同, 避免多個 CI job 產生一樣的 jitter
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMu   sync.Mutex
+)
+
+// RetryOptions 遇到 GitHub rate limit 或暫時性錯誤時重試的選項
+type RetryOptions struct {
+	// MaxRetries 遇到 rate limit 時最多重試的次數, 0 代表不重試
+	MaxRetries int
+	// BaseDelay 無法得知限制解除的時間時, 第一次重試前的等待時間, 之後每次加倍, 0 代表使用預設的 1 分鐘
+	BaseDelay time.Duration
+	// MaxTransientRetries 遇到 5xx 或暫時性的連線錯誤 (如: timeout, connection reset) 時最多重試的次數, 0 代表不重試, 與 MaxRetries 分開計算
+	// 注意 POST 等 request 可能已在 GitHub 完成只是沒收到 response, 重試後可能會得到已存在的錯誤
+	MaxTransientRetries int
+	// TransientDelay 遇到暫時性錯誤時第一次重試前的等待時間, 之後每次加倍, 0 代表使用預設的 1 秒
+	TransientDelay time.Duration
+	// MaxDelay 每次重試最多等待的時間, 0 代表不限制
+	MaxDelay time.Duration
+	// Jitter 是否在等待時間加上最多 BaseDelay 的隨機時間, 避免大量的 CI job 在限制解除的同一時間重試
+	Jitter bool
+}
+
+func (o RetryOptions) baseDelay() time.Duration {
+	if o.BaseDelay > 0 {
+		return o.BaseDelay
+	}
+	return defaultRetryBaseDelay
+}
+
+// delay 計算第 attempt 次重試前要等待的時間, wait 為 GitHub 告知需等待的時間, 無法得知時為 unknownWait
+func (o RetryOptions) delay(attempt int, wait time.Duration) time.Duration {
+	if wait == unknownWait {
+		wait = o.baseDelay() << uint(attempt-1)
+	}
+	if o.Jitter {
+		jitterMu.Lock()
+		wait += time.Duration(jitterRand.Int63n(int64(o.baseDelay())))
+		jitterMu.Unlock()
+	}
+	if o.MaxDelay > 0 && wait > o.MaxDelay {
+		wait = o.MaxDelay
+	}
+	return wait
+}
+
+// transientDelay 計算遇到暫時性錯誤時第 attempt 次重試前要等待的時間
+func (o RetryOptions) transientDelay(attempt int) time.Duration {
+	base := o.TransientDelay
+	if base <= 0 {
+		base = defaultTransientDelay
+	}
+	wait := base << uint(attempt-1)
+	if o.Jitter {
+		jitterMu.Lock()
+		wait += time.Duration(jitterRand.Int63n(int64(base)))
+		jitterMu.Unlock()
+	}
+	if o.MaxDelay > 0 && wait > o.MaxDelay {
+		wait = o.MaxDelay
+	}
+	return wait
+}
+
+// retryTransport 在遇到 GitHub 的 rate limit 時, 等到限制解除後重試
+// 遇到 5xx 或暫時性的連線錯誤時, 以 backoff 重試; 其他錯誤 (如: 4xx) 皆不重試, 直接回傳
+type retryTransport struct {
+	base http.RoundTripper
+	log  logrus.FieldLogger
+	opts RetryOptions
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limits, transients := 0, 0
+	for {
+		resp, err := t.base.RoundTrip(req)
+		var wait time.Duration
+		if req.Context().Err() == nil && isTransient(resp, err) {
+			if transients >= t.opts.MaxTransientRetries {
+				return resp, err
+			}
+			transients++
+			wait = t.opts.transientDelay(transients)
+			t.log.Debugf("transient error on %s %s: %s, retrying %d/%d in %s", req.Method, req.URL.Path, transientCause(resp, err), transients, t.opts.MaxTransientRetries, wait)
+		} else {
+			if err != nil || limits >= t.opts.MaxRetries {
+				return resp, err
+			}
+			w, limited := rateLimitWait(resp)
+			if !limited {
+				return resp, nil
+			}
+			limits++
+			wait = t.opts.delay(limits, w)
+			t.log.Debugf("rate limit exceeded on %s %s, retrying %d/%d in %s", req.Method, req.URL.Path, limits, t.opts.MaxRetries, wait)
+		}
+		retry, ok := rewind(req)
+		if !ok { // request body 無法重新讀取, 不重試
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		req = retry
+	}
+}
+
+// isTransient 判斷是否為 GitHub 短暫的異常 (5xx) 或暫時性的連線錯誤, 這類錯誤通常重試就會成功
+func isTransient(resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+	if e, ok := err.(net.Error); ok && (e.Timeout() || e.Temporary()) {
+		return true
+	}
+	// connection reset 不被 net.Error 視為 temporary, 但通常是連線被中間的 proxy 或 load balancer 切斷, 可以重試
+	if e, ok := err.(*net.OpError); ok {
+		if se, ok := e.Err.(*os.SyscallError); ok {
+			return se.Err == syscall.ECONNRESET
+		}
+	}
+	return false
+}
+
+// transientCause 回傳暫時性錯誤的原因, 用來記錄在 log
+func transientCause(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// rateLimitWait 判斷 response 是否為 rate limit 並回傳需要等待的時間, 無法得知需要等待多久時為 unknownWait
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden {
+		return 0, false
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return 0, false
+	}
+	copied := *resp
+	copied.Body = ioutil.NopCloser(bytes.NewReader(b))
+	switch e := github.CheckResponse(&copied).(type) {
+	case *github.RateLimitError:
+		wait := e.Rate.Reset.Time.Sub(now())
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	case *github.AbuseRateLimitError:
+		if e.RetryAfter != nil {
+			return *e.RetryAfter, true
+		}
+		return unknownWait, true
+	}
+	return 0, false
+}
+
+// rewind 複製一個可以重送的 request
+func rewind(req *http.Request) (*http.Request, bool) {
+	retry := new(http.Request)
+	*retry = *req
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry.Body = body
+	return retry, true
+}
@@ -0,0 +1,253 @@
+package github
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func rateLimitedResponse(req *http.Request) *http.Response {
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+	return &http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     h,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"message":"API rate limit exceeded for 127.0.0.1."}`)),
+		Request:    req,
+	}
+}
+
+func okResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+		Request:    req,
+	}
+}
+
+func TestRetryTransport_RateLimit(t *testing.T) {
+	attempts := 0
+	rt := &retryTransport{
+		log:  logrus.StandardLogger(),
+		opts: RetryOptions{MaxRetries: 2},
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts < 2 {
+				return rateLimitedResponse(req), nil
+			}
+			return okResponse(req), nil
+		}),
+	}
+	req, _ := http.NewRequest("GET", "https://api.github.com/repos/softleader/s2i/releases/latest", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status code should be 200, but got %d", resp.StatusCode)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts should be 2, but got %d", attempts)
+	}
+}
+
+func TestRetryTransport_NotRetryOtherErrors(t *testing.T) {
+	attempts := 0
+	rt := &retryTransport{
+		log:  logrus.StandardLogger(),
+		opts: RetryOptions{MaxRetries: 2},
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Not Found"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+	req, _ := http.NewRequest("GET", "https://api.github.com/repos/softleader/s2i/releases/latest", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status code should be 404, but got %d", resp.StatusCode)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts should be 1, but got %d", attempts)
+	}
+}
+
+func TestRetryTransport_Transient(t *testing.T) {
+	failures := []func(req *http.Request) (*http.Response, error){
+		func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway", Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+		},
+		func(req *http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+		},
+	}
+	for _, fail := range failures {
+		attempts := 0
+		rt := &retryTransport{
+			log:  logrus.StandardLogger(),
+			opts: RetryOptions{MaxTransientRetries: 2, TransientDelay: time.Millisecond},
+			base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				if attempts < 3 {
+					return fail(req)
+				}
+				return okResponse(req), nil
+			}),
+		}
+		req, _ := http.NewRequest("POST", "https://api.github.com/repos/softleader/s2i/releases", strings.NewReader(`{"tag_name":"v1.0.0"}`))
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("status code should be 200, but got %d", resp.StatusCode)
+		}
+		if attempts != 3 {
+			t.Errorf("attempts should be 3, but got %d", attempts)
+		}
+	}
+}
+
+func TestRetryTransport_TransientExhausted(t *testing.T) {
+	attempts := 0
+	rt := &retryTransport{
+		log:  logrus.StandardLogger(),
+		opts: RetryOptions{MaxTransientRetries: 2, TransientDelay: time.Millisecond},
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if req.URL.Path == "/repos/softleader/s2i/releases/tags/v1.0.0" {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader(`{"message":"Not Found"}`)), Request: req}, nil
+			}
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(strings.NewReader("")), Request: req}, nil
+		}),
+	}
+	req, _ := http.NewRequest("GET", "https://api.github.com/repos/softleader/s2i/releases/latest", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts != 3 {
+		t.Errorf("should give up with 503 after 3 attempts, but got %d after %d attempts", resp.StatusCode, attempts)
+	}
+
+	attempts = 0
+	req, _ = http.NewRequest("GET", "https://api.github.com/repos/softleader/s2i/releases/tags/v1.0.0", nil)
+	if resp, err = rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound || attempts != 1 {
+		t.Errorf("4xx should not be retried, but got %d after %d attempts", resp.StatusCode, attempts)
+	}
+}
+
+func TestTransientDelay(t *testing.T) {
+	opts := RetryOptions{MaxDelay: 3 * time.Second}
+	if d := opts.transientDelay(1); d != defaultTransientDelay {
+		t.Errorf("delay should be default transient delay %s, but got %s", defaultTransientDelay, d)
+	}
+	if d := opts.transientDelay(2); d != 2*time.Second {
+		t.Errorf("delay of 2nd retry should be doubled to 2s, but got %s", d)
+	}
+	if d := opts.transientDelay(3); d != 3*time.Second {
+		t.Errorf("delay should be capped at 3s, but got %s", d)
+	}
+}
+
+func TestNewClientTimeoutPerRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			time.Sleep(300 * time.Millisecond) // 超過 timeout, 視為暫時性錯誤再重試
+		default:
+			w.Write([]byte(`{"default_branch":"main"}`))
+		}
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	// 重試前的等待時間比 timeout 長, timeout 若是限制總時間則永遠無法重試成功
+	SetClientOptions(&ClientOptions{
+		BaseURL: server.URL + "/",
+		Timeout: 100 * time.Millisecond,
+		Retry:   RetryOptions{MaxTransientRetries: 2, TransientDelay: 200 * time.Millisecond},
+	})
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+	client, err := newClient(context.Background(), logrus.StandardLogger(), ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := client.Repositories.Get(context.Background(), "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.GetDefaultBranch() != "main" || attempts != 3 {
+		t.Errorf("should succeed after 3 attempts, but got %q after %d attempts", r.GetDefaultBranch(), attempts)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	defer func() { now = time.Now }()
+	reset := time.Unix(1570000000, 0)
+	now = func() time.Time { return reset.Add(-90 * time.Second) }
+
+	resp := rateLimitedResponse(nil)
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	wait, limited := rateLimitWait(resp)
+	if !limited || wait != 90*time.Second {
+		t.Errorf("should wait 90s until rate limit reset, but got %s (limited: %v)", wait, limited)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	opts := RetryOptions{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	if d := opts.delay(1, 3*time.Second); d != 3*time.Second {
+		t.Errorf("delay should be the wait told by GitHub 3s, but got %s", d)
+	}
+	if d := opts.delay(2, unknownWait); d != 2*time.Second {
+		t.Errorf("delay of 2nd retry should be doubled to 2s, but got %s", d)
+	}
+	if d := opts.delay(4, unknownWait); d != 5*time.Second {
+		t.Errorf("delay should be capped at 5s, but got %s", d)
+	}
+	if d := opts.delay(1, 0); d != 0 {
+		t.Errorf("delay should be 0 when rate limit already reset, but got %s", d)
+	}
+
+	opts.Jitter = true
+	opts.MaxDelay = 0
+	for i := 0; i < 10; i++ {
+		if d := opts.delay(1, 3*time.Second); d < 3*time.Second || d >= 4*time.Second {
+			t.Errorf("delay with jitter should be in [3s, 4s), but got %s", d)
+		}
+	}
+	if d := (RetryOptions{}).delay(1, unknownWait); d != defaultRetryBaseDelay {
+		t.Errorf("delay should be default base delay %s, but got %s", defaultRetryBaseDelay, d)
+	}
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	scopesHeader = "X-Oauth-Scopes"
+)
+
+// releaseScopes 建立 release 所需的 classic token scope, 任一即可; public_repo 只能在 public repo 建立 release
+var releaseScopes = []string{"repo", "public_repo"}
+
+// TokenScopes 回傳 classic token 的 scopes, 取自任一認證過的 response 中的 X-OAuth-Scopes header
+// classic 為 false 代表 response 沒有該 header (如: fine-grained token 或 GitHub App 的 token), 無法得知 token 的權限
+func TokenScopes(ctx context.Context, log logrus.FieldLogger, token string) (scopes []string, classic bool, err error) {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return nil, false, err
+	}
+	return c.TokenScopes(ctx)
+}
+
+// TokenScopes 同 package function TokenScopes
+func (c *Client) TokenScopes(ctx context.Context) (scopes []string, classic bool, err error) {
+	scopes, classic, err = tokenScopes(ctx, c.log, c.limits)
+	return scopes, classic, wrapError(err)
+}
+
+// WarnMissingScopes 確認 token 有建立 release 所需的 scopes, 缺少時輸出警告, 方便在 release 失敗前找出 token 設定錯誤
+// fine-grained token 無法得知其權限, 只會提示需要 Contents 的 read and write 權限
+func WarnMissingScopes(ctx context.Context, log logrus.FieldLogger, token string) error {
+	c, err := NewClient(ctx, log, token)
+	if err != nil {
+		return err
+	}
+	return c.WarnMissingScopes(ctx)
+}
+
+// WarnMissingScopes 同 package function WarnMissingScopes
+func (c *Client) WarnMissingScopes(ctx context.Context) error {
+	scopes, classic, err := c.TokenScopes(ctx)
+	if err != nil {
+		return err
+	}
+	warnMissingScopes(c.log, scopes, classic)
+	return nil
+}
+
+func tokenScopes(ctx context.Context, log logrus.FieldLogger, limits rateLimitsService) ([]string, bool, error) {
+	log.Debugf("fetching scopes of token")
+	_, resp, err := limits.RateLimits(ctx) // 不會消耗 rate limit
+	if err != nil {
+		return nil, false, err
+	}
+	if _, found := resp.Header[scopesHeader]; !found {
+		log.Debugf("no %s header in response, token may be fine-grained", scopesHeader)
+		return nil, false, nil
+	}
+	scopes := parseScopes(resp.Header.Get(scopesHeader))
+	log.Debugf("scopes of token: %v", scopes)
+	return scopes, true, nil
+}
+
+// parseScopes 解析以 "," 分隔的 scopes, 如: "repo, read:org"
+func parseScopes(header string) []string {
+	var scopes []string
+	for _, s := range strings.Split(header, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func warnMissingScopes(log logrus.FieldLogger, scopes []string, classic bool) {
+	if !classic {
+		log.Debugln("unable to detect permissions of fine-grained token, make sure it has been granted read and write access to Contents")
+		return
+	}
+	for _, s := range scopes {
+		for _, required := range releaseScopes {
+			if s == required {
+				return
+			}
+		}
+	}
+	log.Warnf("GitHub token is missing the scope to create releases, requires one of %v but got %v", releaseScopes, scopes)
+}
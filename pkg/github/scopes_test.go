@@ -0,0 +1,64 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenScopes(t *testing.T) {
+	scopes := "repo, read:org"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if scopes != "-" {
+			w.Header().Set("X-OAuth-Scopes", scopes)
+		}
+		w.Write([]byte(`{"resources":{}}`))
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	SetClientOptions(&ClientOptions{BaseURL: server.URL + "/"})
+	log := logrus.StandardLogger()
+	hc := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	c, err := NewClientWithHTTPClient(log, hc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, classic, err := c.TokenScopes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !classic || !reflect.DeepEqual(got, []string{"repo", "read:org"}) {
+		t.Errorf("scopes should be [repo read:org], but got %v (classic: %v)", got, classic)
+	}
+
+	scopes = "-"
+	if got, classic, err = c.TokenScopes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if classic || len(got) != 0 {
+		t.Errorf("token without scopes header should not be classic, but got %v", got)
+	}
+}
+
+func TestWarnMissingScopes(t *testing.T) {
+	log := logrus.New()
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+
+	warnMissingScopes(log, []string{"public_repo"}, true)
+	warnMissingScopes(log, nil, false)
+	if b.Len() != 0 {
+		t.Errorf("should not warn, but got %q", b.String())
+	}
+	warnMissingScopes(log, []string{"read:org"}, true)
+	if !strings.Contains(b.String(), "missing the scope") {
+		t.Errorf("should warn the missing scope, but got %q", b.String())
+	}
+}
@@ -0,0 +1,252 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// repositoriesService 封裝了會使用到的 github.RepositoriesService methods, 方便在測試時替換成 mock
+type repositoriesService interface {
+	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	GetBranch(ctx context.Context, owner, repo, branch string) (*github.Branch, *github.Response, error)
+	ListBranches(ctx context.Context, owner string, repo string, opt *github.ListOptions) ([]*github.Branch, *github.Response, error)
+	ListTags(ctx context.Context, owner string, repo string, opt *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error)
+	CompareCommits(ctx context.Context, owner, repo string, base, head string, opt *github.ListOptions) (*github.CommitsComparison, *github.Response, error)
+	ListCommits(ctx context.Context, owner, repo string, opt *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+	GetCommitSHA1(ctx context.Context, owner, repo, ref, lastSHA string) (string, *github.Response, error)
+	CreateComment(ctx context.Context, owner, repo, sha string, comment *github.RepositoryComment) (*github.RepositoryComment, *github.Response, error)
+	ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+	GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error)
+	GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error)
+	CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	CreateReleaseRequest(ctx context.Context, owner, repo string, req *releaseRequest) (*github.RepositoryRelease, *github.Response, error)
+	EditRelease(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	DeleteRelease(ctx context.Context, owner, repo string, id int64) (*github.Response, error)
+	ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opt *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error)
+	UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opt *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error)
+	DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error)
+	DownloadReleaseAssetContent(ctx context.Context, owner, repo string, id int64) (io.ReadCloser, error)
+	CreateDeployment(ctx context.Context, owner, repo string, request *github.DeploymentRequest) (*github.Deployment, *github.Response, error)
+	CreateDeploymentStatus(ctx context.Context, owner, repo string, deployment int64, request *github.DeploymentStatusRequest) (*github.DeploymentStatus, *github.Response, error)
+	Dispatch(ctx context.Context, owner, repo string, opts dispatchRequestOptions) (*github.Response, error)
+}
+
+// gitService 封裝了會使用到的 github.GitService methods, 方便在測試時替換成 mock
+type gitService interface {
+	GetRef(ctx context.Context, owner string, repo string, ref string) (*github.Reference, *github.Response, error)
+	DeleteRef(ctx context.Context, owner string, repo string, ref string) (*github.Response, error)
+	CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error)
+	CreateTag(ctx context.Context, owner string, repo string, tag *github.Tag) (*github.Tag, *github.Response, error)
+	GetTag(ctx context.Context, owner string, repo string, sha string) (*github.Tag, *github.Response, error)
+	GetCommit(ctx context.Context, owner string, repo string, sha string) (*github.Commit, *github.Response, error)
+}
+
+// repositories 以 github.RepositoriesService 為基礎, 補上 go-github v28 尚未支援的 API
+type repositories struct {
+	*github.RepositoriesService
+	client *github.Client
+	log    logrus.FieldLogger
+	opts   ClientOptions
+	// download 跟隨 asset 下載的 redirect 時使用的 transport, 不會帶上認證
+	download http.RoundTripper
+}
+
+// newRepositoriesService 將 github client 的 RepositoriesService 包裝成 repositoriesService, opts 為建立 Client 時的 ClientOptions
+func newRepositoriesService(client *github.Client, log logrus.FieldLogger, opts ClientOptions, download http.RoundTripper) repositoriesService {
+	return &repositories{
+		RepositoriesService: client.Repositories,
+		client:              client,
+		log:                 log,
+		opts:                opts,
+		download:            download,
+	}
+}
+
+// CreateRelease 同 github.RepositoriesService.CreateRelease, 建立後剩餘的 rate limit 過低時輸出警告
+func (r *repositories) CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	rr, resp, err := r.RepositoriesService.CreateRelease(ctx, owner, repo, release)
+	if err == nil {
+		warnRateLimit(r.log, r.opts.RateLimitWarning, resp)
+	}
+	return rr, resp, err
+}
+
+// CompareCommits 比較 base...head 之間的 commits, 可透過 opt 分頁
+func (r *repositories) CompareCommits(ctx context.Context, owner, repo string, base, head string, opt *github.ListOptions) (*github.CommitsComparison, *github.Response, error) {
+	u := fmt.Sprintf("repos/%v/%v/compare/%v...%v", owner, repo, base, head)
+	if opt != nil {
+		u = fmt.Sprintf("%s?page=%d&per_page=%d", u, opt.Page, opt.PerPage)
+	}
+	req, err := r.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	comp := new(github.CommitsComparison)
+	resp, err := r.client.Do(ctx, req, comp)
+	if err != nil {
+		return nil, resp, err
+	}
+	return comp, resp, nil
+}
+
+// releaseRequest 建立 release 的 request body, 補上 go-github v28 尚未支援的 generate_release_notes, make_latest 及 discussion_category_name
+type releaseRequest struct {
+	*github.RepositoryRelease
+	GenerateReleaseNotes   bool   `json:"generate_release_notes,omitempty"`
+	MakeLatest             string `json:"make_latest,omitempty"`
+	DiscussionCategoryName string `json:"discussion_category_name,omitempty"`
+}
+
+// CreateReleaseRequest 以 go-github v28 尚未支援的欄位建立 release
+func (r *repositories) CreateReleaseRequest(ctx context.Context, owner, repo string, release *releaseRequest) (*github.RepositoryRelease, *github.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/releases", owner, repo)
+	req, err := r.client.NewRequest("POST", u, release)
+	if err != nil {
+		return nil, nil, err
+	}
+	rr := new(github.RepositoryRelease)
+	resp, err := r.client.Do(ctx, req, rr)
+	if err != nil {
+		return nil, resp, err
+	}
+	warnRateLimit(r.log, r.opts.RateLimitWarning, resp)
+	return rr, resp, nil
+}
+
+// UploadReleaseAsset 上傳檔案到 release, 同 github.RepositoriesService.UploadReleaseAsset
+// 但以 contextReader 讀取檔案, ctx 被取消時會立即中斷上傳, 不會等到整個大檔案傳完
+func (r *repositories) UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opt *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if stat.IsDir() {
+		return nil, nil, errors.New("the asset to upload can't be a directory")
+	}
+	q := url.Values{}
+	q.Set("name", opt.Name)
+	if opt.Label != "" {
+		q.Set("label", opt.Label)
+	}
+	u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?%s", owner, repo, id, q.Encode())
+	req, err := r.client.NewUploadRequest(u, &contextReader{ctx: ctx, r: file}, stat.Size(), opt.MediaType)
+	if err != nil {
+		return nil, nil, err
+	}
+	asset := new(github.ReleaseAsset)
+	resp, err := r.client.Do(ctx, req, asset)
+	if err != nil {
+		return nil, resp, err
+	}
+	return asset, resp, nil
+}
+
+// contextReader 在 ctx 被取消後, 之後的 Read 皆回傳 ctx 的錯誤
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// DownloadReleaseAssetContent 下載 release asset 的內容
+// GitHub 通常會回傳 redirect 到儲存空間的預先簽署網址, 此時改以不帶 token 的 r.download 下載, 否則儲存空間會拒絕帶有其他認證的 request
+func (r *repositories) DownloadReleaseAssetContent(ctx context.Context, owner, repo string, id int64) (io.ReadCloser, error) {
+	rc, redirectURL, err := r.DownloadReleaseAsset(ctx, owner, repo, id)
+	if err != nil || redirectURL == "" {
+		return rc, err
+	}
+	req, err := http.NewRequest("GET", redirectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	hc := &http.Client{Transport: r.download}
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download asset-id %d from redirected url: %s", id, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// dispatchRequestOptions 觸發 repository_dispatch 時的 request body
+type dispatchRequestOptions struct {
+	EventType     string           `json:"event_type"`
+	ClientPayload *json.RawMessage `json:"client_payload,omitempty"`
+}
+
+// Dispatch 觸發 repository_dispatch event
+func (r *repositories) Dispatch(ctx context.Context, owner, repo string, opts dispatchRequestOptions) (*github.Response, error) {
+	u := fmt.Sprintf("repos/%v/%v/dispatches", owner, repo)
+	req, err := r.client.NewRequest("POST", u, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.Do(ctx, req, nil)
+}
+
+// newGitService 將 github client 的 GitService 包裝成 gitService
+func newGitService(client *github.Client) gitService {
+	return client.Git
+}
+
+// issuesService 封裝了會使用到的 github.IssuesService methods, 方便在測試時替換成 mock
+type issuesService interface {
+	ListMilestones(ctx context.Context, owner string, repo string, opt *github.MilestoneListOptions) ([]*github.Milestone, *github.Response, error)
+}
+
+// newIssuesService 將 github client 的 IssuesService 包裝成 issuesService
+func newIssuesService(client *github.Client) issuesService {
+	return client.Issues
+}
+
+// rateLimitsService 封裝了會使用到的 github.Client.RateLimits, 方便在測試時替換成 mock
+type rateLimitsService interface {
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
+}
+
+// actionsService 封裝了會使用到的 GitHub Actions API, go-github v28 尚未支援, 因此自行實作
+type actionsService interface {
+	CreateWorkflowDispatchEventByFileName(ctx context.Context, owner, repo, workflowFileName string, event workflowDispatchEvent) (*github.Response, error)
+}
+
+// workflowDispatchEvent 觸發 workflow_dispatch 時的 request body
+type workflowDispatchEvent struct {
+	Ref    string            `json:"ref"`
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+type actions struct {
+	client *github.Client
+}
+
+// newActionsService 以 github client 建立 actionsService
+func newActionsService(client *github.Client) actionsService {
+	return &actions{client: client}
+}
+
+// CreateWorkflowDispatchEventByFileName 以 workflow 的檔名觸發 workflow_dispatch event
+func (a *actions) CreateWorkflowDispatchEventByFileName(ctx context.Context, owner, repo, workflowFileName string, event workflowDispatchEvent) (*github.Response, error) {
+	u := fmt.Sprintf("repos/%v/%v/actions/workflows/%v/dispatches", owner, repo, workflowFileName)
+	req, err := a.client.NewRequest("POST", u, &event)
+	if err != nil {
+		return nil, err
+	}
+	return a.client.Do(ctx, req, nil)
+}
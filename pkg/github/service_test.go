@@ -0,0 +1,1771 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/go-github/v28/github"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockRepositories 只實作測試用到的 methods, 其他 methods 被呼叫時會 panic
+type mockRepositories struct {
+	repositoriesService
+	mu       sync.Mutex
+	latest   *github.RepositoryRelease
+	releases map[string]*github.RepositoryRelease
+	created  []*github.RepositoryRelease
+	edited   []*github.RepositoryRelease
+	deleted  []int64
+	deploys  []*github.DeploymentRequest
+	events   []dispatchRequestOptions
+	commits  []*github.RepositoryCommit
+	assets   map[int64][]*github.ReleaseAsset
+	uploaded []string
+	removed  []int64
+	notes    int
+	latests  []string
+	comments map[string][]string
+	branches []string
+	repo     *github.Repository
+	tags     []string
+}
+
+func newMockRepositories(releases ...*github.RepositoryRelease) *mockRepositories {
+	m := &mockRepositories{releases: make(map[string]*github.RepositoryRelease)}
+	for _, rr := range releases {
+		m.releases[rr.GetTagName()] = rr
+		m.latest = rr
+	}
+	return m
+}
+
+func notFound() error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}, Message: "Not Found"}
+}
+
+func (m *mockRepositories) Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error) {
+	if m.repo == nil {
+		return nil, nil, notFound()
+	}
+	return m.repo, nil, nil
+}
+
+func (m *mockRepositories) GetBranch(ctx context.Context, owner, repo, branch string) (*github.Branch, *github.Response, error) {
+	for _, b := range m.branches {
+		if b == branch {
+			return &github.Branch{Name: github.String(b)}, nil, nil
+		}
+	}
+	return nil, nil, notFound()
+}
+
+// ListBranches 每頁只回傳一個 branch, 用來測試分頁
+func (m *mockRepositories) ListBranches(ctx context.Context, owner string, repo string, opt *github.ListOptions) ([]*github.Branch, *github.Response, error) {
+	if opt.Page > len(m.branches) {
+		return nil, &github.Response{}, nil
+	}
+	resp := &github.Response{}
+	if opt.Page < len(m.branches) {
+		resp.NextPage = opt.Page + 1
+	}
+	return []*github.Branch{{Name: github.String(m.branches[opt.Page-1])}}, resp, nil
+}
+
+func (m *mockRepositories) GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error) {
+	if m.latest == nil {
+		return nil, nil, notFound()
+	}
+	return m.latest, nil, nil
+}
+
+func (m *mockRepositories) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*github.RepositoryRelease, *github.Response, error) {
+	rr, found := m.releases[tag]
+	if !found {
+		return nil, nil, notFound()
+	}
+	return rr, nil, nil
+}
+
+func (m *mockRepositories) CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if repo == "forbidden" {
+		return nil, nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden}, Message: "Forbidden"}
+	}
+	m.created = append(m.created, release)
+	m.releases[release.GetTagName()] = release
+	created := *release
+	created.ID = github.Int64(42)
+	return &created, nil, nil
+}
+
+func (m *mockRepositories) CreateReleaseRequest(ctx context.Context, owner, repo string, req *releaseRequest) (*github.RepositoryRelease, *github.Response, error) {
+	if req.GenerateReleaseNotes {
+		m.notes++
+	}
+	m.latests = append(m.latests, req.MakeLatest)
+	if req.DiscussionCategoryName == "Missing" {
+		return nil, nil, &github.ErrorResponse{
+			Response: &http.Response{StatusCode: http.StatusUnprocessableEntity},
+			Message:  "Validation Failed",
+			Errors:   []github.Error{{Resource: "Release", Field: "discussion_category_name", Code: "invalid"}},
+		}
+	}
+	return m.CreateRelease(ctx, owner, repo, req.RepositoryRelease)
+}
+
+func (m *mockRepositories) DeleteRelease(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	m.deleted = append(m.deleted, id)
+	return nil, nil
+}
+
+func (m *mockRepositories) CreateDeployment(ctx context.Context, owner, repo string, request *github.DeploymentRequest) (*github.Deployment, *github.Response, error) {
+	m.deploys = append(m.deploys, request)
+	return &github.Deployment{ID: github.Int64(int64(len(m.deploys)))}, nil, nil
+}
+
+func (m *mockRepositories) ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	var releases []*github.RepositoryRelease
+	for _, rr := range m.releases {
+		releases = append(releases, rr)
+	}
+	return releases, &github.Response{}, nil
+}
+
+func (m *mockRepositories) Dispatch(ctx context.Context, owner, repo string, opts dispatchRequestOptions) (*github.Response, error) {
+	m.events = append(m.events, opts)
+	return nil, nil
+}
+
+func (m *mockRepositories) CompareCommits(ctx context.Context, owner, repo string, base, head string, opt *github.ListOptions) (*github.CommitsComparison, *github.Response, error) {
+	cc := &github.CommitsComparison{}
+	for _, c := range m.commits {
+		cc.Commits = append(cc.Commits, *c)
+	}
+	return cc, &github.Response{}, nil
+}
+
+func (m *mockRepositories) ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opt *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error) {
+	assets := m.assets[id]
+	if opt.Page == 1 && len(assets) > 1 { // 模擬分頁, 第一頁只回傳一個 asset
+		return assets[:1], &github.Response{NextPage: 2}, nil
+	}
+	if opt.Page > 1 {
+		assets = assets[1:]
+	}
+	return assets, &github.Response{}, nil
+}
+
+func (m *mockRepositories) UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opt *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error) {
+	m.uploaded = append(m.uploaded, opt.Name)
+	return &github.ReleaseAsset{Name: github.String(opt.Name), BrowserDownloadURL: github.String("https://github.com/softleader/s2i/releases/download/" + opt.Name)}, nil, nil
+}
+
+func (m *mockRepositories) DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	m.removed = append(m.removed, id)
+	return nil, nil
+}
+
+func (m *mockRepositories) CreateComment(ctx context.Context, owner, repo, sha string, comment *github.RepositoryComment) (*github.RepositoryComment, *github.Response, error) {
+	if m.comments == nil {
+		m.comments = make(map[string][]string)
+	}
+	m.comments[sha] = append(m.comments[sha], comment.GetBody())
+	return &github.RepositoryComment{HTMLURL: github.String("https://github.com/softleader/s2i/commit/" + sha)}, nil, nil
+}
+
+func (m *mockRepositories) ListCommits(ctx context.Context, owner, repo string, opt *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return m.commits, &github.Response{}, nil
+}
+
+// GetCommitSHA1 以 m.branches 及 m.commits 解析 ref, 符合 commit sha 開頭的 ref 視為縮寫的 sha
+func (m *mockRepositories) GetCommitSHA1(ctx context.Context, owner, repo, ref, lastSHA string) (string, *github.Response, error) {
+	for _, b := range m.branches {
+		if b == ref {
+			return strings.Repeat("a", 40), nil, nil
+		}
+	}
+	for _, c := range m.commits {
+		if strings.HasPrefix(c.GetSHA(), ref) {
+			return c.GetSHA(), nil, nil
+		}
+	}
+	return "", nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnprocessableEntity}, Message: "No commit found for SHA: " + ref}
+}
+
+func (m *mockRepositories) ListTags(ctx context.Context, owner string, repo string, opt *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+	var tags []*github.RepositoryTag
+	for _, name := range m.tags {
+		tags = append(tags, &github.RepositoryTag{Name: github.String(name), Commit: &github.Commit{SHA: github.String("sha-of-" + name)}})
+	}
+	return tags, &github.Response{}, nil
+}
+
+func (m *mockRepositories) EditRelease(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	m.edited = append(m.edited, release)
+	return release, nil, nil
+}
+
+// mockGit 只實作測試用到的 methods, 其他 methods 被呼叫時會 panic
+type mockGit struct {
+	gitService
+	refs    []string
+	deleted []string
+	tags    []*github.Tag
+	objects map[string]*github.GitObject
+	commits map[string]*github.Commit
+	errs    map[string]error
+}
+
+func (m *mockGit) GetTag(ctx context.Context, owner string, repo string, sha string) (*github.Tag, *github.Response, error) {
+	return &github.Tag{SHA: github.String(sha), Object: m.objects[sha]}, nil, nil
+}
+
+func (m *mockGit) GetCommit(ctx context.Context, owner string, repo string, sha string) (*github.Commit, *github.Response, error) {
+	c, found := m.commits[sha]
+	if !found {
+		return nil, nil, notFound()
+	}
+	return c, nil, nil
+}
+
+func (m *mockGit) CreateTag(ctx context.Context, owner string, repo string, tag *github.Tag) (*github.Tag, *github.Response, error) {
+	m.tags = append(m.tags, tag)
+	created := *tag
+	created.SHA = github.String("tag-object-sha")
+	return &created, nil, nil
+}
+
+func (m *mockGit) CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error) {
+	m.refs = append(m.refs, ref.GetRef())
+	return ref, nil, nil
+}
+
+func (m *mockGit) DeleteRef(ctx context.Context, owner, repo, ref string) (*github.Response, error) {
+	if err, found := m.errs["refs/"+ref]; found {
+		return nil, err
+	}
+	m.deleted = append(m.deleted, "refs/"+ref)
+	return nil, nil
+}
+
+func (m *mockGit) GetRef(ctx context.Context, owner, repo, ref string) (*github.Reference, *github.Response, error) {
+	var matches []string
+	for _, r := range m.refs {
+		if strings.HasPrefix(r, "refs/"+ref) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, notFound()
+	case 1:
+		return &github.Reference{Ref: github.String(matches[0]), Object: m.objects[matches[0]]}, nil, nil
+	default:
+		return nil, &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}, errors.New("multiple matches")
+	}
+}
+
+func TestFindNextReleaseVersion(t *testing.T) {
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	next, err := findNextReleaseVersion(ctx, log, newMockRepositories(), "softleader", "s2i", &NextVersionOptions{VPrefix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v0.1.0" {
+		t.Errorf("next version of repo without release should be v0.1.0, but got %q", next)
+	}
+
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3")})
+	next, err = findNextReleaseVersion(ctx, log, repos, "softleader", "s2i", &NextVersionOptions{Bump: BumpMinor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.3.0" {
+		t.Errorf("next minor version of v1.2.3 should be v1.3.0, but got %q", next)
+	}
+
+	next, err = findNextReleaseVersion(ctx, log, repos, "softleader", "s2i", &NextVersionOptions{EnforceVPrefix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "1.2.4" {
+		t.Errorf("next version of v1.2.3 without enforced v prefix should be 1.2.4, but got %q", next)
+	}
+}
+
+func TestFindNextReleaseVersionFromTags(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3")})
+	repos.tags = []string{"v1.2.3", "latest", "v1.10.0", "v1.9.0"}
+
+	next, err := findNextReleaseVersion(context.Background(), logrus.StandardLogger(), repos, "softleader", "s2i", &NextVersionOptions{FromTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.10.1" {
+		t.Errorf("next version from tags should be v1.10.1, but got %q", next)
+	}
+	highest, err := findHighestRepositoryTag(context.Background(), logrus.StandardLogger(), repos, "softleader", "s2i", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha := highest.GetCommit().GetSHA(); sha != "sha-of-v1.10.0" {
+		t.Errorf("sha of the highest tag should be sha-of-v1.10.0, but got %q", sha)
+	}
+
+	repos.tags = []string{"latest"}
+	next, err = findNextReleaseVersion(context.Background(), logrus.StandardLogger(), repos, "softleader", "s2i", &NextVersionOptions{FromTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "0.1.0" {
+		t.Errorf("next version of repo without semver tag should be 0.1.0, but got %q", next)
+	}
+}
+
+func TestFindNextReleaseVersionStableOnly(t *testing.T) {
+	repos := newMockRepositories(
+		&github.RepositoryRelease{TagName: github.String("v1.2.3")},
+		&github.RepositoryRelease{TagName: github.String("v1.3.0-rc.1"), Prerelease: github.Bool(true)},
+		&github.RepositoryRelease{TagName: github.String("v1.4.0"), Draft: github.Bool(true)},
+		&github.RepositoryRelease{TagName: github.String("v1.5.0-beta")},
+	)
+	repos.tags = []string{"v1.2.3", "v1.3.0-rc.1", "v1.2.4"}
+	log := logrus.StandardLogger()
+
+	next, err := findNextReleaseVersion(context.Background(), log, repos, "softleader", "s2i", &NextVersionOptions{StableOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.2.4" {
+		t.Errorf("next version of stable releases should be v1.2.4, but got %q", next)
+	}
+	next, err = findNextReleaseVersion(context.Background(), log, repos, "softleader", "s2i", &NextVersionOptions{StableOnly: true, FromTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.2.5" {
+		t.Errorf("next version of stable tags should be v1.2.5, but got %q", next)
+	}
+}
+
+func TestFindNextReleaseVersionOfChannel(t *testing.T) {
+	log := logrus.StandardLogger()
+	tests := []struct {
+		tags     []string
+		channel  string
+		expected string
+	}{
+		{[]string{"v1.1.0", "v1.2.0-beta.3", "v1.2.0-rc.1", "v1.2.0-beta.2"}, "beta", "v1.2.0-beta.4"},
+		{[]string{"v1.2.0", "v1.2.0-beta.3"}, "beta", "v1.2.1-beta.1"},
+		{[]string{"1.2.0", "1.3.0-alpha.1"}, "beta", "1.2.1-beta.1"},
+		{[]string{"v1.3.0-beta.1", "v1.2.0"}, ChannelStable, "v1.2.1"},
+		{nil, "beta", "0.1.0-beta.1"},
+	}
+	for _, test := range tests {
+		repos := newMockRepositories()
+		for _, tag := range test.tags {
+			repos.releases[tag] = &github.RepositoryRelease{TagName: github.String(tag)}
+		}
+		next, err := findNextReleaseVersion(context.Background(), log, repos, "softleader", "s2i", &NextVersionOptions{Channel: test.channel})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != test.expected {
+			t.Errorf("next %s version of %v should be %s, but got %s", test.channel, test.tags, test.expected, next)
+		}
+	}
+	if _, err := findNextReleaseVersion(context.Background(), log, newMockRepositories(), "softleader", "s2i", &NextVersionOptions{Channel: "be ta"}); KindOf(err) != KindInvalid {
+		t.Errorf("invalid channel should be invalid, but got %v", err)
+	}
+}
+
+func TestCreateRelease(t *testing.T) {
+	repos := newMockRepositories()
+	release, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.3", &ReleaseOptions{Name: "v1.2.3 is out"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.TagName != "v1.2.3" {
+		t.Errorf("tag name should be v1.2.3, but got %q", release.TagName)
+	}
+	if release.ID != 42 {
+		t.Errorf("release id should be 42, but got %d", release.ID)
+	}
+	if len(repos.created) != 1 || repos.created[0].GetTargetCommitish() != "master" {
+		t.Fatalf("should create 1 release targeting master, but got %v", repos.created)
+	}
+	if name := repos.created[0].GetName(); name != "v1.2.3 is out" {
+		t.Errorf("name should be %q, but got %q", "v1.2.3 is out", name)
+	}
+	if body := repos.created[0].Body; body != nil {
+		t.Errorf("body should be omitted, but got %q", *body)
+	}
+}
+
+func TestCreateReleaseWithLogEntry(t *testing.T) {
+	log := logrus.New()
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+	entry := log.WithField("correlation-id", "abc123")
+
+	if _, err := createRelease(context.Background(), entry, newMockRepositories(), nil, "softleader", "s2i", "master", "v1.2.3", nil); err != nil {
+		t.Fatal(err)
+	}
+	if out := b.String(); !strings.Contains(out, "correlation-id=abc123") {
+		t.Errorf("log should carry the fields of entry, but got %q", out)
+	}
+}
+
+func TestCreateReleaseIdempotent(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.3"), TargetCommitish: github.String("master")})
+	opts := &ReleaseOptions{Idempotent: true, RequireNewer: true}
+
+	release, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.3", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.ID != 7 {
+		t.Errorf("should return the existing release 7, but got %d", release.ID)
+	}
+	if len(repos.created) != 0 {
+		t.Errorf("should not create any release, but created %d", len(repos.created))
+	}
+
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "develop", "v1.2.3", opts); KindOf(err) != KindInvalid {
+		t.Errorf("existing release targeting another branch should be invalid, but got %v", err)
+	}
+
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.4", opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.created) != 1 {
+		t.Errorf("should create release when absent, but created %d", len(repos.created))
+	}
+}
+
+func TestCreatePrereleaseIdempotent(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.3-rc.1"), TargetCommitish: github.String("master"), Prerelease: github.Bool(true)})
+	opts := &ReleaseOptions{Idempotent: true}
+	c := &Client{log: logrus.StandardLogger(), repos: repos, git: &mockGit{}}
+
+	release, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.3-rc.1", false, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.ID != 7 {
+		t.Errorf("should return the existing pre-release 7, but got %d", release.ID)
+	}
+	if len(repos.created) != 0 {
+		t.Errorf("should not create any release, but created %d", len(repos.created))
+	}
+
+	if _, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "develop", "v1.2.3-rc.1", false, opts); KindOf(err) != KindInvalid {
+		t.Errorf("existing pre-release targeting another branch should be invalid, but got %v", err)
+	}
+	if _, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.3-rc.1", true, opts); KindOf(err) != KindInvalid {
+		t.Errorf("force with idempotent should be invalid, but got %v", err)
+	}
+
+	if _, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.3-rc.2", false, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.created) != 1 {
+		t.Errorf("should create pre-release when absent, but created %d", len(repos.created))
+	}
+}
+
+func TestCreateReleaseWithExistingTag(t *testing.T) {
+	repos := newMockRepositories()
+	git := &mockGit{refs: []string{"refs/tags/v1.2.3"}}
+	opts := &ReleaseOptions{ExistingTag: true, TagMessage: "v1.2.3"}
+
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, git, "softleader", "s2i", "master", "v1.2.3", opts); err != nil {
+		t.Fatal(err)
+	}
+	if target := repos.created[0].TargetCommitish; target != nil {
+		t.Errorf("release of existing tag should not specify target, but got %q", *target)
+	}
+	if len(git.tags) != 0 {
+		t.Errorf("should not create annotated tag for existing tag, but got %v", git.tags)
+	}
+
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, &mockGit{}, "softleader", "s2i", "master", "v1.2.4", &ReleaseOptions{ExistingTag: true}); err != nil {
+		t.Fatal(err)
+	}
+	if target := repos.created[1].GetTargetCommitish(); target != "master" {
+		t.Errorf("release of absent tag should target master, but got %q", target)
+	}
+}
+
+func TestCreatePrereleaseWithExistingTag(t *testing.T) {
+	repos := newMockRepositories()
+	git := &mockGit{refs: []string{"refs/tags/v1.2.3-rc.1"}}
+	opts := &ReleaseOptions{ExistingTag: true, TagMessage: "v1.2.3-rc.1"}
+	c := &Client{log: logrus.StandardLogger(), repos: repos, git: git}
+
+	if _, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.3-rc.1", false, opts); err != nil {
+		t.Fatal(err)
+	}
+	if target := repos.created[0].TargetCommitish; target != nil {
+		t.Errorf("pre-release of existing tag should not specify target, but got %q", *target)
+	}
+	if len(git.tags) != 0 {
+		t.Errorf("should not create annotated tag for existing tag, but got %v", git.tags)
+	}
+	if _, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.3-rc.1", true, opts); KindOf(err) != KindInvalid {
+		t.Errorf("force with existing tag should be invalid, but got %v", err)
+	}
+	if len(git.deleted) != 0 {
+		t.Errorf("should not delete existing tag, but deleted %v", git.deleted)
+	}
+
+	c.git = &mockGit{}
+	if _, err := c.CreatePrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.4-rc.1", false, &ReleaseOptions{ExistingTag: true}); err != nil {
+		t.Fatal(err)
+	}
+	if target := repos.created[1].GetTargetCommitish(); target != "master" {
+		t.Errorf("pre-release of absent tag should target master, but got %q", target)
+	}
+}
+
+func TestCreateReleaseGeneratingNotes(t *testing.T) {
+	repos := newMockRepositories()
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.3", &ReleaseOptions{GenerateReleaseNotes: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createPrerelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.4-rc.1", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if repos.notes != 1 || len(repos.created) != 2 {
+		t.Errorf("should generate release notes only when asked, but generated %d of %d", repos.notes, len(repos.created))
+	}
+}
+
+func TestReleaseRequest(t *testing.T) {
+	b, err := json.Marshal(&releaseRequest{RepositoryRelease: &github.RepositoryRelease{TagName: github.String("v1.2.3")}, GenerateReleaseNotes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"tag_name":"v1.2.3","generate_release_notes":true}`; string(b) != expected {
+		t.Errorf("request body should be %s, but got %s", expected, b)
+	}
+}
+
+func TestCreateReleaseTargetingCommit(t *testing.T) {
+	repos := newMockRepositories()
+	sha := "ec5365ad1a31edd35446b04738aee99dfbf8a7d4"
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", sha, "v1.2.3", nil); err != nil {
+		t.Fatal(err)
+	}
+	if target := repos.created[0].GetTargetCommitish(); target != sha {
+		t.Errorf("target commitish should be %s, but got %q", sha, target)
+	}
+}
+
+func TestCreateReleaseWithAnnotatedTag(t *testing.T) {
+	repos, git := newMockRepositories(), &mockGit{}
+	sha := "ec5365ad1a31edd35446b04738aee99dfbf8a7d4"
+	opts := &ReleaseOptions{TagMessage: "release v1.2.3"}
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, git, "softleader", "s2i", sha, "v1.2.3", opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(git.tags) != 1 || git.tags[0].GetMessage() != "release v1.2.3" || git.tags[0].GetObject().GetSHA() != sha {
+		t.Fatalf("should create annotated tag on %s, but got %v", sha, git.tags)
+	}
+	if len(git.refs) != 1 || git.refs[0] != "refs/tags/v1.2.3" {
+		t.Errorf("should create refs/tags/v1.2.3, but got %v", git.refs)
+	}
+	if len(repos.created) != 1 {
+		t.Errorf("should create 1 release, but got %v", repos.created)
+	}
+}
+
+func TestCreateReleaseRequireNewer(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3")})
+	log := logrus.StandardLogger()
+	opts := &ReleaseOptions{RequireNewer: true}
+
+	if _, err := createRelease(context.Background(), log, repos, nil, "softleader", "s2i", "master", "v1.2.0", opts); KindOf(err) != KindInvalid {
+		t.Errorf("creating an older release should return an invalid error, but got %v", err)
+	}
+	if _, err := createRelease(context.Background(), log, repos, nil, "softleader", "s2i", "master", "v1.3.0", opts); err != nil {
+		t.Errorf("creating a newer release should be fine, but got %v", err)
+	}
+}
+
+func TestUpdateRelease(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.2.3")})
+	log := logrus.StandardLogger()
+
+	if _, err := updateRelease(context.Background(), log, repos, "softleader", "s2i", "v1.2.3", "", "changelog"); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.edited) != 1 {
+		t.Fatalf("should edit 1 release, but got %v", repos.edited)
+	}
+	if name := repos.edited[0].Name; name != nil {
+		t.Errorf("name should be unchanged, but got %q", *name)
+	}
+	if body := repos.edited[0].GetBody(); body != "changelog" {
+		t.Errorf("body should be %q, but got %q", "changelog", body)
+	}
+
+	if _, err := updateRelease(context.Background(), log, repos, "softleader", "s2i", "v9.9.9", "name", ""); err == nil {
+		t.Error("updating release of a missing tag should return an error")
+	}
+}
+
+func TestTagExists(t *testing.T) {
+	git := &mockGit{refs: []string{"refs/tags/v1.0.0", "refs/tags/v1.1.0", "refs/tags/v2.0.0"}}
+	log := logrus.StandardLogger()
+	for tag, expected := range map[string]bool{
+		"v1.0.0": true,
+		"v1":     false,
+		"v2":     false,
+		"v3.0.0": false,
+	} {
+		exists, err := tagExists(context.Background(), log, git, "softleader", "s2i", tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != expected {
+			t.Errorf("existence of tag %q should be %v, but got %v", tag, expected, exists)
+		}
+	}
+}
+
+func TestCreateReleases(t *testing.T) {
+	repos := newMockRepositories()
+	targets := []ReleaseTarget{
+		{"softleader", "s2i", "master", "v1.0.0"},
+		{"softleader", "forbidden", "master", "v1.0.0"},
+		{"softleader", "", "master", "v1.0.0"},
+		{"softleader", "slctl", "master", "v2.0.0"},
+	}
+	results := createReleases(context.Background(), logrus.StandardLogger(), repos, nil, targets, 2, nil)
+	if len(results) != len(targets) {
+		t.Fatalf("should have %d results, but got %d", len(targets), len(results))
+	}
+	for i, r := range results {
+		if r.Target != targets[i] {
+			t.Errorf("result %d should be of target %v, but got %v", i, targets[i], r.Target)
+		}
+	}
+	for _, i := range []int{0, 3} {
+		if r := results[i]; r.Err != nil || r.Release == nil || r.Release.TagName != targets[i].Tag {
+			t.Errorf("release of %q should be created, but got %v (err: %v)", targets[i].Repo, r.Release, r.Err)
+		}
+	}
+	if kind := KindOf(results[1].Err); kind != KindUnauthorized {
+		t.Errorf("error kind of forbidden repo should be %v, but got %v", KindUnauthorized, kind)
+	}
+	if kind := KindOf(results[2].Err); kind != KindInvalid {
+		t.Errorf("error kind of missing repo should be %v, but got %v", KindInvalid, kind)
+	}
+	if len(repos.created) != 2 {
+		t.Errorf("should create 2 releases, but got %d", len(repos.created))
+	}
+}
+
+func TestDeleteReleaseOnly(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.2.3")})
+	log := logrus.StandardLogger()
+
+	if err := deleteReleaseOnly(context.Background(), log, repos, "softleader", "s2i", "v1.2.3", true); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.deleted) != 0 {
+		t.Fatalf("dry run should not delete any release, but got %v", repos.deleted)
+	}
+	if err := deleteReleaseOnly(context.Background(), log, repos, "softleader", "s2i", "v1.2.3", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.deleted) != 1 || repos.deleted[0] != 1 {
+		t.Errorf("should delete release 1, but got %v", repos.deleted)
+	}
+	if err := deleteReleaseOnly(context.Background(), log, repos, "softleader", "s2i", "v9.9.9", false); KindOf(err) != KindNotFound {
+		t.Errorf("deleting release of a missing tag should return a not found error, but got %v", err)
+	}
+}
+
+func TestDeleteRelease(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.2.3")})
+	git := &mockGit{refs: []string{"refs/tags/v1.2.3"}}
+	c := &Client{log: logrus.StandardLogger(), repos: repos, git: git}
+
+	if err := c.DeleteRelease(context.Background(), "softleader", "s2i", "v1.2.3", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.deleted) != 1 || repos.deleted[0] != 1 {
+		t.Errorf("should delete release 1, but got %v", repos.deleted)
+	}
+	if len(git.deleted) != 1 || git.deleted[0] != "refs/tags/v1.2.3" {
+		t.Errorf("should delete refs/tags/v1.2.3, but got %v", git.deleted)
+	}
+
+	repos, git = newMockRepositories(), &mockGit{}
+	c = &Client{log: logrus.StandardLogger(), repos: repos, git: git}
+	if err := c.DeleteRelease(context.Background(), "softleader", "s2i", "v9.9.9", false); KindOf(err) != KindNotFound {
+		t.Errorf("deleting a missing tag should return a not found error, but got %v", err)
+	}
+	if len(repos.deleted) != 0 || len(git.deleted) != 0 {
+		t.Errorf("should not delete anything when tag is missing, but got releases %v and refs %v", repos.deleted, git.deleted)
+	}
+}
+
+func TestCreateDeployment(t *testing.T) {
+	repos := newMockRepositories()
+	log := logrus.StandardLogger()
+
+	id, err := createDeployment(context.Background(), log, repos, "softleader", "s2i", "v1.2.3", "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Errorf("deployment id should be 1, but got %d", id)
+	}
+	if d := repos.deploys[0]; d.GetRef() != "v1.2.3" || d.GetEnvironment() != "production" || d.GetAutoMerge() {
+		t.Errorf("should deploy v1.2.3 to production without auto merge, but got %+v", d)
+	}
+	if _, err := createDeployment(context.Background(), log, repos, "softleader", "s2i", "v1.2.3", ""); KindOf(err) != KindInvalid {
+		t.Errorf("deploying without environment should return an invalid error, but got %v", err)
+	}
+	if err := createDeploymentStatus(context.Background(), log, repos, "softleader", "s2i", id, "done"); KindOf(err) != KindInvalid {
+		t.Errorf("unsupported deployment state should return an invalid error, but got %v", err)
+	}
+}
+
+func TestPromoteRelease(t *testing.T) {
+	repos := newMockRepositories(
+		&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.2.3")},
+		&github.RepositoryRelease{ID: github.Int64(2), TagName: github.String("v1.3.0-rc.1"), Prerelease: github.Bool(true)},
+	)
+	log := logrus.StandardLogger()
+
+	if _, err := promoteRelease(context.Background(), log, repos, "softleader", "s2i", "v1.3.0-rc.1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.edited) != 1 || repos.edited[0].Prerelease == nil || repos.edited[0].GetPrerelease() {
+		t.Fatalf("should edit pre-release to be a release, but got %v", repos.edited)
+	}
+	if _, err := promoteRelease(context.Background(), log, repos, "softleader", "s2i", "v1.2.3"); KindOf(err) != KindInvalid {
+		t.Errorf("promoting a release should return an invalid error, but got %v", err)
+	}
+}
+
+func TestPrunePrereleases(t *testing.T) {
+	defer func() { now = time.Now }()
+	today := time.Date(2019, 10, 31, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return today }
+	daysAgo := func(days int) *github.Timestamp {
+		return &github.Timestamp{Time: today.AddDate(0, 0, -days)}
+	}
+	repos := newMockRepositories(
+		&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.0.0"), PublishedAt: daysAgo(60)},
+		&github.RepositoryRelease{ID: github.Int64(2), TagName: github.String("v1.1.0-rc.1"), Prerelease: github.Bool(true), PublishedAt: daysAgo(40)},
+		&github.RepositoryRelease{ID: github.Int64(3), TagName: github.String("v1.1.0-rc.2"), Prerelease: github.Bool(true), PublishedAt: daysAgo(10)},
+	)
+	git := &mockGit{}
+	log := logrus.StandardLogger()
+
+	pruned, err := prunePrereleases(context.Background(), log, repos, git, "softleader", "s2i", 30*24*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 || pruned[0] != "v1.1.0-rc.1" {
+		t.Errorf("should prune v1.1.0-rc.1, but got %v", pruned)
+	}
+	if len(repos.deleted) != 0 || len(git.deleted) != 0 {
+		t.Fatalf("dry run should not delete anything, but got releases %v and refs %v", repos.deleted, git.deleted)
+	}
+
+	if _, err := prunePrereleases(context.Background(), log, repos, git, "softleader", "s2i", 30*24*time.Hour, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.deleted) != 1 || repos.deleted[0] != 2 {
+		t.Errorf("should delete release 2, but got %v", repos.deleted)
+	}
+	if len(git.deleted) != 1 || git.deleted[0] != "refs/tags/v1.1.0-rc.1" {
+		t.Errorf("should delete refs/tags/v1.1.0-rc.1, but got %v", git.deleted)
+	}
+}
+
+type mockActions struct {
+	workflows  map[string]bool
+	dispatched []workflowDispatchEvent
+}
+
+func (m *mockActions) CreateWorkflowDispatchEventByFileName(ctx context.Context, owner, repo, workflowFileName string, event workflowDispatchEvent) (*github.Response, error) {
+	if !m.workflows[workflowFileName] {
+		return nil, notFound()
+	}
+	m.dispatched = append(m.dispatched, event)
+	return nil, nil
+}
+
+func TestDispatchWorkflow(t *testing.T) {
+	actions := &mockActions{workflows: map[string]bool{"deploy.yml": true}}
+	log := logrus.StandardLogger()
+
+	if err := dispatchWorkflow(context.Background(), log, actions, "softleader", "s2i", "deploy.yml", "v1.2.3", map[string]string{"env": "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions.dispatched) != 1 || actions.dispatched[0].Ref != "v1.2.3" || actions.dispatched[0].Inputs["env"] != "prod" {
+		t.Errorf("should dispatch deploy.yml on v1.2.3, but got %v", actions.dispatched)
+	}
+	if err := dispatchWorkflow(context.Background(), log, actions, "softleader", "s2i", "missing.yml", "v1.2.3", nil); KindOf(err) != KindNotFound {
+		t.Errorf("dispatching a missing workflow should return a not found error, but got %v", err)
+	}
+}
+
+func TestDispatchRepository(t *testing.T) {
+	repos := newMockRepositories()
+	log := logrus.StandardLogger()
+
+	payload := map[string]string{"tag": "v1.2.3"}
+	if err := dispatchRepository(context.Background(), log, repos, "softleader", "s2i", "released", payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.events) != 1 || repos.events[0].EventType != "released" {
+		t.Fatalf("should dispatch released event, but got %v", repos.events)
+	}
+	if p := string(*repos.events[0].ClientPayload); p != `{"tag":"v1.2.3"}` {
+		t.Errorf("client payload should be the marshaled payload, but got %s", p)
+	}
+	if err := dispatchRepository(context.Background(), log, repos, "softleader", "s2i", "", nil); KindOf(err) != KindInvalid {
+		t.Errorf("dispatching without event type should return an invalid error, but got %v", err)
+	}
+}
+
+func TestCommitsSinceLatestRelease(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3")})
+	repos.commits = []*github.RepositoryCommit{
+		{SHA: github.String("a1"), Author: &github.User{Login: github.String("softleader")}, Commit: &github.Commit{Message: github.String("feat: foo")}},
+		{SHA: github.String("b2"), Commit: &github.Commit{Author: &github.CommitAuthor{Name: github.String("Matt")}, Message: github.String("fix: bar")}},
+	}
+	log := logrus.StandardLogger()
+
+	for _, r := range []*mockRepositories{repos, {commits: repos.commits}} {
+		commits, err := commitsSinceLatestRelease(context.Background(), log, r, "softleader", "s2i", "master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(commits) != 2 {
+			t.Fatalf("should have 2 commits, but got %d", len(commits))
+		}
+		if c := commits[0]; c.SHA != "a1" || c.Author != "softleader" || c.Message != "feat: foo" {
+			t.Errorf("unexpected commit: %+v", c)
+		}
+		if c := commits[1]; c.Author != "Matt" {
+			t.Errorf("author should fallback to git author name, but got %q", c.Author)
+		}
+	}
+}
+
+func TestGetReleaseAssets(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.3")})
+	repos.assets = map[int64][]*github.ReleaseAsset{
+		7: {
+			{Name: github.String("s2i-linux.tgz"), Size: github.Int(1024), DownloadCount: github.Int(3), BrowserDownloadURL: github.String("https://github.com/softleader/s2i/releases/download/v1.2.3/s2i-linux.tgz")},
+			{Name: github.String("s2i-darwin.tgz"), Size: github.Int(2048)},
+		},
+	}
+	log := logrus.StandardLogger()
+
+	assets, err := getReleaseAssets(context.Background(), log, repos, "softleader", "s2i", "v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 2 {
+		t.Fatalf("should list 2 assets across pages, but got %d", len(assets))
+	}
+	if a := assets[0]; a.Name != "s2i-linux.tgz" || a.Size != 1024 || a.DownloadCount != 3 || a.BrowserDownloadURL == "" {
+		t.Errorf("unexpected asset: %+v", a)
+	}
+
+	if _, err := getReleaseAssets(context.Background(), log, repos, "softleader", "s2i", "v0.0.1"); KindOf(err) != KindNotFound {
+		t.Errorf("release not found should be not found, but got %v", err)
+	}
+}
+
+func TestVerifyTagCommit(t *testing.T) {
+	signed := "ec5365ad1a31edd35446b04738aee99dfbf8a7d4"
+	git := &mockGit{
+		refs: []string{"refs/tags/v1.0.0", "refs/tags/v2.0.0", "refs/heads/master"},
+		objects: map[string]*github.GitObject{
+			"refs/tags/v1.0.0":  {Type: github.String("commit"), SHA: github.String("unsigned")},
+			"refs/tags/v2.0.0":  {Type: github.String("tag"), SHA: github.String("tag-object-sha")},
+			"tag-object-sha":    {Type: github.String("commit"), SHA: github.String(signed)},
+			"refs/heads/master": {Type: github.String("commit"), SHA: github.String("unsigned")},
+		},
+		commits: map[string]*github.Commit{
+			"unsigned": {Verification: &github.SignatureVerification{Verified: github.Bool(false), Reason: github.String("unsigned")}},
+			signed:     {Verification: &github.SignatureVerification{Verified: github.Bool(true), Reason: github.String("valid")}},
+		},
+	}
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	sha, err := resolveTagCommit(ctx, log, git, "softleader", "s2i", "v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != signed {
+		t.Errorf("annotated tag should be resolved to commit %s, but got %s", signed, sha)
+	}
+	if verified, reason, err := verifyCommit(ctx, log, git, "softleader", "s2i", "unsigned"); err != nil || verified || reason != "unsigned" {
+		t.Errorf("commit should not be verified because unsigned, but got %v %q (%v)", verified, reason, err)
+	}
+	if _, err := resolveTagCommit(ctx, log, git, "softleader", "s2i", "v3.0.0"); KindOf(err) != KindNotFound {
+		t.Errorf("missing tag should be not found, but got %v", err)
+	}
+
+	repos := newMockRepositories()
+	opts := &ReleaseOptions{RequireSignedCommit: true}
+	if _, err := createRelease(ctx, log, repos, git, "softleader", "s2i", "master", "v3.0.0", opts); KindOf(err) != KindInvalid {
+		t.Errorf("release of unsigned commit should be invalid, but got %v", err)
+	}
+	if _, err := createRelease(ctx, log, repos, git, "softleader", "s2i", signed, "v3.0.0", opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.ExistingTag = true
+	if _, err := createRelease(ctx, log, repos, git, "softleader", "s2i", "master", "v2.0.0", opts); err != nil {
+		t.Errorf("release of existing signed tag should be created, but got %v", err)
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	repos := newMockRepositories()
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+	if _, err := getRepository(ctx, log, repos, "softleader", "s2i"); KindOf(err) != KindNotFound {
+		t.Errorf("missing repository should be not found, but got %v", err)
+	}
+
+	repos.repo = &github.Repository{
+		Name:          github.String("s2i"),
+		DefaultBranch: github.String("master"),
+		Archived:      github.Bool(true),
+		Permissions:   &map[string]bool{"admin": false, "push": true, "pull": true},
+	}
+	r, err := getRepository(ctx, log, repos, "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Archived || !r.CanPush() || r.DefaultBranch != "master" {
+		t.Errorf("unexpected repository: %+v", r)
+	}
+	if _, err := createRelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.3", &ReleaseOptions{RefuseArchived: true}); KindOf(err) != KindInvalid {
+		t.Errorf("release into archived repository should be invalid, but got %v", err)
+	}
+	if len(repos.created) != 0 {
+		t.Errorf("should not create release into archived repository, but created %d", len(repos.created))
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	git := &mockGit{errs: map[string]error{
+		"refs/heads/master":      &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnprocessableEntity}, Message: "Cannot delete this protected branch"},
+		"refs/heads/not-exist":   &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnprocessableEntity}, Message: "Reference does not exist"},
+		"refs/heads/release/2.0": errors.New("connection reset"),
+	}}
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	if err := deleteBranch(ctx, log, git, "softleader", "s2i", "release/1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if len(git.deleted) != 1 || git.deleted[0] != "refs/heads/release/1.0" {
+		t.Errorf("should delete refs/heads/release/1.0, but got %v", git.deleted)
+	}
+	if err := deleteBranch(ctx, log, git, "softleader", "s2i", "master"); KindOf(err) != KindInvalid {
+		t.Errorf("protected branch should be invalid, but got %v", err)
+	}
+	if err := deleteBranch(ctx, log, git, "softleader", "s2i", "not-exist"); KindOf(err) != KindNotFound {
+		t.Errorf("missing branch should be not found, but got %v", err)
+	}
+	if err := deleteBranch(ctx, log, git, "softleader", "s2i", "release/2.0"); err == nil || KindOf(err) != KindUnknown {
+		t.Errorf("other errors should be returned as is, but got %v", err)
+	}
+}
+
+func TestListBranches(t *testing.T) {
+	repos := newMockRepositories()
+	repos.branches = []string{"master", "release/1.0", "release/1.1", "release/1.1/hotfix", "feature/release"}
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	glob, err := NewGlobMatcher([]string{"release/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches, err := listBranches(ctx, log, repos, "softleader", "s2i", glob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "release/1.0,release/1.1"; strings.Join(branches, ",") != expected {
+		t.Errorf("branches should be %s, but got %v", expected, branches)
+	}
+
+	regex, err := NewRegexMatcher([]string{"^release/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches, err = listBranches(ctx, log, repos, "softleader", "s2i", regex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 3 {
+		t.Errorf("should match 3 branches by regexp, but got %v", branches)
+	}
+
+	branches, err = listBranches(ctx, log, repos, "softleader", "s2i", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != len(repos.branches) {
+		t.Errorf("should list all branches without matcher, but got %v", branches)
+	}
+
+	if _, err := NewGlobMatcher([]string{"release/["}); err == nil {
+		t.Errorf("malformed glob pattern should be an error")
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	repos := newMockRepositories()
+	repos.branches = []string{"master"}
+	repos.commits = []*github.RepositoryCommit{{SHA: github.String("3f786850e387550fdab836ed7e6dc881de23001b")}}
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	sha, err := resolveRef(ctx, log, repos, "softleader", "s2i", "3f78685")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != "3f786850e387550fdab836ed7e6dc881de23001b" {
+		t.Errorf("short sha should be resolved to 3f786850e387550fdab836ed7e6dc881de23001b, but got %s", sha)
+	}
+	if sha, err = resolveRef(ctx, log, repos, "softleader", "s2i", "master"); err != nil || len(sha) != 40 {
+		t.Errorf("branch should be resolved to full sha, but got %q (%v)", sha, err)
+	}
+	if _, err := resolveRef(ctx, log, repos, "softleader", "s2i", "not-exist"); KindOf(err) != KindNotFound {
+		t.Errorf("missing ref should be not found, but got %v", err)
+	}
+	if _, err := (&Client{log: log, repos: repos}).ResolveRef(ctx, "softleader", "s2i", ""); KindOf(err) != KindInvalid {
+		t.Errorf("empty ref should be invalid, but got %v", err)
+	}
+}
+
+func TestCreatePrereleaseOutOfOrder(t *testing.T) {
+	repos := newMockRepositories(
+		&github.RepositoryRelease{TagName: github.String("v1.2.0-rc.3"), Prerelease: github.Bool(true)},
+		&github.RepositoryRelease{TagName: github.String("v1.1.0-rc.9"), Prerelease: github.Bool(true)},
+	)
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.0-rc.2", false, &ReleaseOptions{RequireNewer: true}); KindOf(err) != KindInvalid {
+		t.Errorf("out-of-order pre-release should be invalid, but got %v", err)
+	}
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.0-rc.2", false, &ReleaseOptions{CheckPrereleaseOrder: true}); err != nil {
+		t.Errorf("out-of-order pre-release should only be warned, but got %v", err)
+	}
+	for _, tag := range []string{"v1.2.0-rc.4", "v1.1.1-rc.1"} {
+		if err := ensurePrereleaseOrder(ctx, log, repos, "softleader", "s2i", tag); err != nil {
+			t.Errorf("%s should be in order, but got %v", tag, err)
+		}
+	}
+}
+
+// unlistableRepositories 模擬無法列出 release, 如: token 權限不足
+type unlistableRepositories struct {
+	*mockRepositories
+}
+
+func (m *unlistableRepositories) ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	return nil, nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden}, Message: "Resource not accessible by integration"}
+}
+
+func TestCreatePrereleaseOrderCheckFailed(t *testing.T) {
+	repos := &unlistableRepositories{newMockRepositories()}
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.0-rc.2", false, &ReleaseOptions{CheckPrereleaseOrder: true}); err != nil {
+		t.Errorf("failing to list releases should only be warned, but got %v", err)
+	}
+	if len(repos.created) != 1 {
+		t.Errorf("pre-release should be created, but got %d", len(repos.created))
+	}
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.0-rc.3", false, &ReleaseOptions{RequireNewer: true}); err == nil {
+		t.Errorf("failing to list releases should be an error when RequireNewer")
+	}
+}
+
+func TestGetReleaseURL(t *testing.T) {
+	u := "https://github.com/softleader/s2i/releases/tag/v1.2.3"
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3"), HTMLURL: github.String(u)})
+	log := logrus.StandardLogger()
+
+	actual, err := getReleaseURL(context.Background(), log, repos, "softleader", "s2i", "v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != u {
+		t.Errorf("release url should be %s, but got %s", u, actual)
+	}
+	if _, err := getReleaseURL(context.Background(), log, repos, "softleader", "s2i", "v0.0.1"); KindOf(err) != KindNotFound {
+		t.Errorf("tag without release should be not found, but got %v", err)
+	}
+}
+
+func TestClientReusedAcrossCalls(t *testing.T) {
+	ctx := context.Background()
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3")})
+	c := &Client{log: logrus.StandardLogger(), repos: repos, git: &mockGit{}}
+
+	next, err := c.FindNextReleaseVersion(ctx, "softleader", "s2i", &NextVersionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "v1.2.4" {
+		t.Errorf("next version of v1.2.3 should be v1.2.4, but got %q", next)
+	}
+	if _, err := c.CreateRelease(ctx, "softleader", "s2i", "master", next, &ReleaseOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := repos.releases["v1.2.4"]; !found {
+		t.Errorf("release v1.2.4 should be created by the same client")
+	}
+	if _, err := c.CreateRelease(ctx, "", "s2i", "master", "v1.2.5", &ReleaseOptions{}); KindOf(err) != KindInvalid {
+		t.Errorf("release without owner should be KindInvalid, but got %v", err)
+	}
+}
+
+func TestCreateReleaseMakeLatest(t *testing.T) {
+	repos := newMockRepositories()
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "1.x", "v1.9.1", &ReleaseOptions{MakeLatest: MakeLatestFalse}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.latests) != 1 || repos.latests[0] != MakeLatestFalse {
+		t.Errorf("make_latest should be sent as %q, but got %v", MakeLatestFalse, repos.latests)
+	}
+	if _, err := CreateRelease(context.Background(), logrus.StandardLogger(), "", "softleader", "s2i", "1.x", "v1.9.2", &ReleaseOptions{MakeLatest: "yes", DryRun: true}); KindOf(err) != KindInvalid {
+		t.Errorf("unsupported make-latest should be KindInvalid, but got %v", err)
+	}
+
+	b, err := json.Marshal(&releaseRequest{RepositoryRelease: &github.RepositoryRelease{TagName: github.String("v1.9.1")}, MakeLatest: MakeLatestFalse})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"tag_name":"v1.9.1","make_latest":"false"}`; string(b) != expected {
+		t.Errorf("request body should be %s, but got %s", expected, b)
+	}
+}
+
+func TestFindPreviousReleaseTag(t *testing.T) {
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+	repos := newMockRepositories(
+		&github.RepositoryRelease{TagName: github.String("v1.10.0")},
+		&github.RepositoryRelease{TagName: github.String("v1.2.0")},
+		&github.RepositoryRelease{TagName: github.String("v1.9.0-rc.1")},
+		&github.RepositoryRelease{TagName: github.String("nightly")},
+		&github.RepositoryRelease{TagName: github.String("v2.0.0")},
+	)
+	for tag, expected := range map[string]string{
+		"v2.0.0":  "v1.10.0",
+		"v1.10.0": "v1.9.0-rc.1",
+		"v1.9.0":  "v1.9.0-rc.1",
+		"v1.2.0":  "",
+		"v3.0.0":  "v2.0.0",
+	} {
+		previous, err := findPreviousReleaseTag(ctx, log, repos, "softleader", "s2i", tag, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if previous != expected {
+			t.Errorf("previous tag of %s should be %q, but got %q", tag, expected, previous)
+		}
+	}
+	if _, err := findPreviousReleaseTag(ctx, log, repos, "softleader", "s2i", "nightly", false); KindOf(err) != KindInvalid {
+		t.Errorf("non-semver tag should be KindInvalid, but got %v", err)
+	}
+}
+
+func TestDeleteTagOnly(t *testing.T) {
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+	git := &mockGit{refs: []string{"refs/tags/v1.2.3"}}
+	c := &Client{log: log, git: git}
+
+	if err := c.DeleteTag(ctx, "softleader", "s2i", "v1.2.3", true); err != nil {
+		t.Fatal(err)
+	}
+	if len(git.deleted) != 0 {
+		t.Errorf("dry-run should not delete any ref, but deleted %v", git.deleted)
+	}
+	if err := c.DeleteTag(ctx, "softleader", "s2i", "v1.2.3", false); err != nil {
+		t.Fatal(err)
+	}
+	if len(git.deleted) != 1 || git.deleted[0] != "refs/tags/v1.2.3" {
+		t.Errorf("should delete refs/tags/v1.2.3, but deleted %v", git.deleted)
+	}
+	if err := c.DeleteTag(ctx, "softleader", "s2i", "v9.9.9", false); KindOf(err) != KindNotFound {
+		t.Errorf("deleting absent tag should be KindNotFound, but got %v", err)
+	}
+	if err := c.DeleteTag(ctx, "softleader", "s2i", "", false); KindOf(err) != KindInvalid {
+		t.Errorf("deleting empty tag should be KindInvalid, but got %v", err)
+	}
+}
+
+func TestCreateEnvironmentPrerelease(t *testing.T) {
+	repos := newMockRepositories()
+	c := &Client{log: logrus.StandardLogger(), repos: repos, git: &mockGit{}}
+	if _, err := c.CreateEnvironmentPrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.0", "staging", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.created) != 1 || repos.created[0].GetTagName() != "v1.2.0-staging" || !repos.created[0].GetPrerelease() {
+		t.Errorf("should create pre-release v1.2.0-staging, but got %v", repos.created)
+	}
+	if _, err := c.CreateEnvironmentPrerelease(context.Background(), "softleader", "s2i", "master", "v1.2.0", "st@ging", false, nil); KindOf(err) != KindInvalid {
+		t.Errorf("illegal environment should be KindInvalid, but got %v", err)
+	}
+}
+
+// stalledRepositories 模擬 GitHub 沒有回應, 直到 ctx 結束
+type stalledRepositories struct {
+	*mockRepositories
+}
+
+func (m *stalledRepositories) GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error) {
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+func TestFindNextReleaseVersionTimeout(t *testing.T) {
+	c := &Client{log: logrus.StandardLogger(), repos: &stalledRepositories{newMockRepositories()}}
+	_, err := c.FindNextReleaseVersion(context.Background(), "softleader", "s2i", &NextVersionOptions{Timeout: 10 * time.Millisecond})
+	if KindOf(err) != KindTimeout {
+		t.Errorf("stalled GitHub should be KindTimeout, but got %v", err)
+	}
+	if timeout := (&NextVersionOptions{}).timeout(); timeout != defaultFindTimeout {
+		t.Errorf("timeout should default to %s, but got %s", defaultFindTimeout, timeout)
+	}
+}
+
+type mockIssues struct {
+	milestones []*github.Milestone
+	states     []string
+}
+
+func (m *mockIssues) ListMilestones(ctx context.Context, owner, repo string, opt *github.MilestoneListOptions) ([]*github.Milestone, *github.Response, error) {
+	m.states = append(m.states, opt.State)
+	if opt.Page < len(m.milestones) {
+		return m.milestones[opt.Page-1 : opt.Page], &github.Response{NextPage: opt.Page + 1}, nil
+	}
+	return m.milestones[opt.Page-1:], &github.Response{}, nil
+}
+
+func TestFindMilestone(t *testing.T) {
+	issues := &mockIssues{milestones: []*github.Milestone{
+		{Number: github.Int(1), Title: github.String("v1.1.0"), State: github.String("closed"), ClosedIssues: github.Int(12)},
+		{Number: github.Int(2), Title: github.String("v1.2.0"), State: github.String("closed"), ClosedIssues: github.Int(7), OpenIssues: github.Int(1)},
+		{Number: github.Int(3), Title: github.String("v1.3.0"), State: github.String("open")},
+	}}
+	c := &Client{log: logrus.StandardLogger(), issues: issues}
+	m, err := c.FindMilestone(context.Background(), "softleader", "s2i", "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Number != 2 || m.ClosedIssues != 7 || m.OpenIssues != 1 {
+		t.Errorf("milestone v1.2.0 should be #2 with 7 closed and 1 open issues, but got %+v", m)
+	}
+	if issues.states[0] != MilestoneAll {
+		t.Errorf("should find milestone in all states, but got %q", issues.states[0])
+	}
+	if _, err := c.FindMilestone(context.Background(), "softleader", "s2i", "v9.0.0"); KindOf(err) != KindNotFound {
+		t.Errorf("absent milestone should be KindNotFound, but got %v", err)
+	}
+	if _, err := c.ListMilestones(context.Background(), "softleader", "s2i", "done"); KindOf(err) != KindInvalid {
+		t.Errorf("unsupported state should be KindInvalid, but got %v", err)
+	}
+}
+
+func TestCreateReleaseCommentOnCommit(t *testing.T) {
+	sha := "ec5365ad1a31edd35446b04738aee99dfbf8a7d4"
+	repos := newMockRepositories()
+	git := &mockGit{
+		refs:    []string{"refs/tags/v1.2.0"},
+		objects: map[string]*github.GitObject{"refs/tags/v1.2.0": {Type: github.String("commit"), SHA: github.String(sha)}},
+	}
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, git, "softleader", "s2i", "master", "v1.2.0", &ReleaseOptions{CommentOnCommit: true}); err != nil {
+		t.Fatal(err)
+	}
+	if comments := repos.comments[sha]; len(comments) != 1 || !strings.HasPrefix(comments[0], "Released as v1.2.0") {
+		t.Errorf("should comment on released commit %s, but got %v", sha, repos.comments)
+	}
+
+	release, err := createRelease(context.Background(), logrus.StandardLogger(), repos, git, "softleader", "s2i", "master", "v1.3.0", &ReleaseOptions{CommentOnCommit: true})
+	if err != nil {
+		t.Errorf("failing to comment should not fail the release, but got %v", err)
+	} else if release.CommentErr == nil {
+		t.Errorf("failing to comment should be returned in CommentErr")
+	}
+}
+
+func TestCreatePrereleaseCommentOnCommit(t *testing.T) {
+	sha := "ec5365ad1a31edd35446b04738aee99dfbf8a7d4"
+	repos := newMockRepositories()
+	git := &mockGit{
+		refs:    []string{"refs/tags/v1.2.0-rc.1"},
+		objects: map[string]*github.GitObject{"refs/tags/v1.2.0-rc.1": {Type: github.String("commit"), SHA: github.String(sha)}},
+	}
+	release, err := createPrerelease(context.Background(), logrus.StandardLogger(), repos, git, "softleader", "s2i", "master", "v1.2.0-rc.1", false, &ReleaseOptions{CommentOnCommit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.CommentErr != nil {
+		t.Errorf("should comment without error, but got %v", release.CommentErr)
+	}
+	if comments := repos.comments[sha]; len(comments) != 1 || !strings.HasPrefix(comments[0], "Released as v1.2.0-rc.1") {
+		t.Errorf("should comment on released commit %s, but got %v", sha, repos.comments)
+	}
+}
+
+func TestCreatePrereleaseForceOnlyOnSameBranch(t *testing.T) {
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+	opts := &ReleaseOptions{TagMessage: "rc"}
+	newGit := func() *mockGit {
+		return &mockGit{
+			refs:    []string{"refs/heads/master", "refs/heads/develop", "refs/tags/v1.2.0-rc.1"},
+			objects: map[string]*github.GitObject{"refs/heads/master": {SHA: github.String("sha")}, "refs/heads/develop": {SHA: github.String("sha")}},
+		}
+	}
+
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.0-rc.1"), TargetCommitish: github.String("develop")})
+	git := newGit()
+	if _, err := createPrerelease(ctx, log, repos, git, "softleader", "s2i", "master", "v1.2.0-rc.1", true, opts); KindOf(err) != KindInvalid {
+		t.Errorf("forcing release of another branch should be KindInvalid, but got %v", err)
+	}
+	if len(repos.deleted) != 0 || len(git.deleted) != 0 {
+		t.Errorf("release of another branch should not be deleted, but deleted %v and %v", repos.deleted, git.deleted)
+	}
+
+	git = newGit()
+	if _, err := createPrerelease(ctx, log, repos, git, "softleader", "s2i", "develop", "v1.2.0-rc.1", true, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.deleted) != 1 || len(git.deleted) != 1 {
+		t.Errorf("release of the same branch should be deleted, but deleted %v and %v", repos.deleted, git.deleted)
+	}
+}
+
+func TestGetReleaseByTag(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(7), TagName: github.String("v1.2.3"), Draft: github.Bool(true)})
+	c := &Client{log: logrus.StandardLogger(), repos: repos}
+	r, err := c.GetReleaseByTag(context.Background(), "softleader", "s2i", "v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 7 || !r.Draft {
+		t.Errorf("release of v1.2.3 should be draft release-id 7, but got %+v", r)
+	}
+	if _, err := c.GetReleaseByTag(context.Background(), "softleader", "s2i", "v9.9.9"); KindOf(err) != KindNotFound {
+		t.Errorf("absent release should be KindNotFound, but got %v", err)
+	}
+}
+
+func TestCreateReleaseWithDiscussion(t *testing.T) {
+	repos := newMockRepositories()
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.0", &ReleaseOptions{DiscussionCategory: "Announcements"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.created) != 1 {
+		t.Errorf("should create release with discussion, but created %d", len(repos.created))
+	}
+	if _, err := createRelease(context.Background(), logrus.StandardLogger(), repos, nil, "softleader", "s2i", "master", "v1.2.1", &ReleaseOptions{DiscussionCategory: "Missing"}); KindOf(err) != KindInvalid || !strings.Contains(err.Error(), "discussion category") {
+		t.Errorf("missing discussion category should be KindInvalid, but got %v", err)
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+	repos := newMockRepositories()
+	for _, c := range []struct {
+		permissions map[string]bool
+		level       string
+		kind        ErrorKind
+	}{
+		{map[string]bool{"admin": true, "push": true, "pull": true}, "admin", KindUnknown},
+		{map[string]bool{"admin": false, "push": true, "pull": true}, "write", KindUnknown},
+		{map[string]bool{"admin": false, "push": false, "pull": true}, "read", KindUnauthorized},
+	} {
+		permissions := c.permissions
+		repos.repo = &github.Repository{Name: github.String("s2i"), Permissions: &permissions}
+		err := checkPermissions(ctx, log, repos, "softleader", "s2i")
+		if c.kind == KindUnknown && err != nil {
+			t.Errorf("%s permission should be sufficient, but got %v", c.level, err)
+		}
+		if c.kind != KindUnknown && (KindOf(err) != c.kind || !strings.Contains(err.Error(), c.level)) {
+			t.Errorf("%s permission should be %v, but got %v", c.level, c.kind, err)
+		}
+		if r := newRepository(repos.repo); r.PermissionLevel() != c.level {
+			t.Errorf("permission level should be %s, but got %s", c.level, r.PermissionLevel())
+		}
+	}
+}
+
+func TestAppendToReleaseBody(t *testing.T) {
+	repos := newMockRepositories(
+		&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.2.3"), Body: github.String("## Changes\n- fix bug\n")},
+		&github.RepositoryRelease{ID: github.Int64(2), TagName: github.String("v1.2.4")},
+	)
+	c := &Client{log: logrus.StandardLogger(), repos: repos}
+	if _, err := c.AppendToReleaseBody(context.Background(), "softleader", "s2i", "v1.2.3", "## Assets\n- s2i.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AppendToReleaseBody(context.Background(), "softleader", "s2i", "v1.2.4", "## Assets"); err != nil {
+		t.Fatal(err)
+	}
+	if body := repos.edited[0].GetBody(); body != "## Changes\n- fix bug\n\n## Assets\n- s2i.tgz" {
+		t.Errorf("content should be appended after existing body, but got %q", body)
+	}
+	if body := repos.edited[1].GetBody(); body != "## Assets" {
+		t.Errorf("content should be the body of release without body, but got %q", body)
+	}
+	if _, err := c.AppendToReleaseBody(context.Background(), "softleader", "s2i", "v9.9.9", "x"); KindOf(err) != KindNotFound {
+		t.Errorf("absent release should be KindNotFound, but got %v", err)
+	}
+}
+
+func TestCreateReleaseVerifyBranch(t *testing.T) {
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+	repos := newMockRepositories()
+	repos.branches = []string{"master"}
+	opts := &ReleaseOptions{VerifyBranch: true}
+	if _, err := createRelease(ctx, log, repos, nil, "softleader", "s2i", "mastre", "v1.2.3", opts); KindOf(err) != KindNotFound {
+		t.Errorf("release of absent branch should be KindNotFound, but got %v", err)
+	}
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "mastre", "v1.2.3-rc.1", false, opts); KindOf(err) != KindNotFound {
+		t.Errorf("pre-release of absent branch should be KindNotFound, but got %v", err)
+	}
+	if len(repos.created) != 0 {
+		t.Errorf("should not create release of absent branch, but created %d", len(repos.created))
+	}
+	if _, err := createRelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.3", opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createRelease(ctx, log, repos, nil, "softleader", "s2i", "ec5365ad1a31edd35446b04738aee99dfbf8a7d4", "v1.2.4", opts); err != nil {
+		t.Errorf("commit sha should not be checked as branch, but got %v", err)
+	}
+}
+
+func TestCreateReleaseWithSummary(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{TagName: github.String("v1.2.3")})
+	message := func(m string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{SHA: github.String("sha"), Commit: &github.Commit{Message: github.String(m)}}
+	}
+	repos.commits = []*github.RepositoryCommit{
+		message("Merge pull request #12 from softleader/signature\n\nAdd signature upload"),
+		message("Fix typo in README (#13)"),
+		message("chore: bump version"),
+	}
+	c := &Client{log: logrus.StandardLogger(), repos: repos, git: &mockGit{}}
+	summary, err := c.CreateReleaseWithSummary(context.Background(), "softleader", "s2i", "master", "v1.3.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Tag != "v1.3.0" || summary.Previous != "v1.2.3" || summary.CommitCount != 3 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	expected := []string{"Add signature upload (#12)", "Fix typo in README (#13)"}
+	if !reflect.DeepEqual(summary.PullRequests, expected) {
+		t.Errorf("pull requests should be %v, but got %v", expected, summary.PullRequests)
+	}
+}
+
+func TestPublishRelease(t *testing.T) {
+	repos := newMockRepositories(&github.RepositoryRelease{ID: github.Int64(1), TagName: github.String("v1.2.2")})
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	draft, err := createRelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.3", &ReleaseOptions{Draft: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !draft.Draft {
+		t.Errorf("release should be created as draft")
+	}
+	c := &Client{log: log, repos: repos}
+	if _, err := c.PublishRelease(ctx, "softleader", "s2i", "v1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.edited) != 1 || repos.edited[0].Draft == nil || *repos.edited[0].Draft {
+		t.Errorf("draft should be published by editing draft to false, but got %v", repos.edited)
+	}
+
+	for _, tag := range []string{"v1.2.2", "v9.9.9"} {
+		if _, err := c.PublishRelease(ctx, "softleader", "s2i", tag); KindOf(err) != KindNotFound {
+			t.Errorf("publishing %s without draft should be not found, but got %v", tag, err)
+		}
+	}
+}
+
+// noCallRepositories 及 noCallGit 只要任何 method 被呼叫就讓測試失敗
+type noCallRepositories struct {
+	repositoriesService
+	t *testing.T
+}
+
+func (m *noCallRepositories) GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error) {
+	m.t.Fatal("dry-run should not call GetLatestRelease")
+	return nil, nil, nil
+}
+
+func (m *noCallRepositories) CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	m.t.Fatal("dry-run should not call CreateRelease")
+	return nil, nil, nil
+}
+
+func (m *noCallRepositories) ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	m.t.Fatal("dry-run should not call ListReleases")
+	return nil, nil, nil
+}
+
+type noCallGit struct {
+	gitService
+	t *testing.T
+}
+
+func (m *noCallGit) GetRef(ctx context.Context, owner string, repo string, ref string) (*github.Reference, *github.Response, error) {
+	m.t.Fatal("dry-run should not call GetRef")
+	return nil, nil, nil
+}
+
+func TestDryRunWithoutAPICalls(t *testing.T) {
+	defer SetClientOptions(nil)
+	defer os.Setenv(envToken, os.Getenv(envToken))
+	os.Unsetenv(envToken)
+	SetClientOptions(&ClientOptions{TokenFile: filepath.Join(os.TempDir(), "s2i-no-such-token")})
+	ctx := context.Background()
+	log := logrus.StandardLogger()
+	opts := &ReleaseOptions{DryRun: true, RequireNewer: true, ExistingTag: true}
+
+	// 沒有 token 時建立 Client 會失敗 (TokenFile 不存在), dry-run 不需要 token
+	if _, err := CreateRelease(ctx, log, "", "softleader", "s2i", "master", "v1.2.3", opts); err != nil {
+		t.Errorf("dry-run should work without token, but got %v", err)
+	}
+	if _, err := CreatePrerelease(ctx, log, "", "softleader", "s2i", "master", "v1.2.3-rc.1", false, opts); err != nil {
+		t.Errorf("dry-run should work without token, but got %v", err)
+	}
+
+	// 任何 GitHub API 的呼叫都會讓測試失敗, 未實作的 method 被呼叫時則會 panic
+	c := &Client{log: log, repos: &noCallRepositories{t: t}, git: &noCallGit{t: t}}
+	release, err := c.CreateRelease(ctx, "softleader", "s2i", "master", "v1.2.3", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.TagName != "v1.2.3" {
+		t.Errorf("simulated release should be v1.2.3, but got %q", release.TagName)
+	}
+	pre, err := c.CreatePrerelease(ctx, "softleader", "s2i", "master", "v1.2.3-rc.1", false, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pre.Prerelease {
+		t.Errorf("simulated release should be a pre-release")
+	}
+}
+
+func TestCreateReleaseWithSummaryDryRun(t *testing.T) {
+	defer SetClientOptions(nil)
+	defer os.Setenv(envToken, os.Getenv(envToken))
+	os.Unsetenv(envToken)
+	SetClientOptions(&ClientOptions{TokenFile: filepath.Join(os.TempDir(), "s2i-no-such-token")})
+	ctx := context.Background()
+	log := logrus.StandardLogger()
+	opts := &ReleaseOptions{DryRun: true}
+
+	if _, err := CreateReleaseWithSummary(ctx, log, "", "softleader", "s2i", "master", "v1.2.3", opts); err != nil {
+		t.Errorf("dry-run should work without token, but got %v", err)
+	}
+
+	// 任何 GitHub API 的呼叫都會讓測試失敗, 包含比對 commits 及找出上一個 release
+	c := &Client{log: log, repos: &noCallRepositories{t: t}, git: &noCallGit{t: t}}
+	summary, err := c.CreateReleaseWithSummary(ctx, "softleader", "s2i", "master", "v1.2.3", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Tag != "v1.2.3" || summary.CommitCount != 0 {
+		t.Errorf("simulated summary should only contain tag v1.2.3, but got %+v", summary)
+	}
+}
+
+// pagedRepositories 模擬分頁列出 release, 依序回傳 pages 中的每一頁
+type pagedRepositories struct {
+	*mockRepositories
+	pages [][]*github.RepositoryRelease
+	asked []int
+}
+
+func (m *pagedRepositories) ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	m.asked = append(m.asked, opt.Page)
+	resp := &github.Response{LastPage: len(m.pages)}
+	if opt.Page < len(m.pages) {
+		resp.NextPage = opt.Page + 1
+	}
+	return m.pages[opt.Page-1], resp, nil
+}
+
+func TestListReleasesPagination(t *testing.T) {
+	repos := &pagedRepositories{
+		mockRepositories: newMockRepositories(),
+		pages: [][]*github.RepositoryRelease{
+			{{TagName: github.String("v1.1.0")}},
+			{{TagName: github.String("v1.0.0")}},
+		},
+	}
+
+	releases, err := listReleases(context.Background(), logrus.StandardLogger(), repos, "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.asked) != 2 || repos.asked[0] != 1 || repos.asked[1] != 2 {
+		t.Errorf("should fetch page 1 and 2, but got %v", repos.asked)
+	}
+	if len(releases) != 2 || releases[0].GetTagName() != "v1.1.0" || releases[1].GetTagName() != "v1.0.0" {
+		t.Errorf("should list releases of both pages, but got %v", releases)
+	}
+}
+
+func TestGetLatestRelease(t *testing.T) {
+	repos := newMockRepositories()
+	repos.latest = &github.RepositoryRelease{
+		ID:      github.Int64(1),
+		TagName: github.String("v1.2.3"),
+		Body:    github.String("changelog"),
+		HTMLURL: github.String("https://github.com/softleader/s2i/releases/tag/v1.2.3"),
+		Author:  &github.User{Login: github.String("softleader")},
+	}
+	c := &Client{log: logrus.StandardLogger(), repos: repos}
+
+	release, err := c.GetLatestRelease(context.Background(), "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.TagName != "v1.2.3" || release.Body != "changelog" || release.Author.GetLogin() != "softleader" {
+		t.Errorf("should return details of latest release v1.2.3, but got %+v", release)
+	}
+
+	c = &Client{log: logrus.StandardLogger(), repos: newMockRepositories()}
+	if _, err := c.GetLatestRelease(context.Background(), "softleader", "s2i"); KindOf(err) != KindNotFound {
+		t.Errorf("repo without any release should return a not found error, but got %v", err)
+	}
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	repos := newMockRepositories()
+	repos.repo = &github.Repository{DefaultBranch: github.String("develop")}
+	c := &Client{log: logrus.StandardLogger(), repos: repos}
+
+	branch, err := c.GetDefaultBranch(context.Background(), "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "develop" {
+		t.Errorf("default branch should be develop, but got %q", branch)
+	}
+
+	c = &Client{log: logrus.StandardLogger(), repos: newMockRepositories()}
+	_, err = c.GetDefaultBranch(context.Background(), "softleader", "s2i")
+	if _, ok := err.(*Error); !ok {
+		t.Fatalf("error should be wrapped as *Error, but got %T", err)
+	}
+	if kind := KindOf(err); kind != KindNotFound {
+		t.Errorf("error kind of missing repo should be %v, but got %v", KindNotFound, kind)
+	}
+}
+
+func TestCreatePrereleaseWithoutOrderCheck(t *testing.T) {
+	repos := &pagedRepositories{
+		mockRepositories: newMockRepositories(),
+		pages:            [][]*github.RepositoryRelease{{{TagName: github.String("v1.2.0-rc.3")}}},
+	}
+	log := logrus.StandardLogger()
+	ctx := context.Background()
+
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.0-rc.2", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.asked) != 0 {
+		t.Errorf("should not list releases without checking the order, but fetched pages %v", repos.asked)
+	}
+	if _, err := createPrerelease(ctx, log, repos, nil, "softleader", "s2i", "master", "v1.2.0-rc.4", false, &ReleaseOptions{CheckPrereleaseOrder: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos.asked) != 1 {
+		t.Errorf("should list releases to check the order, but fetched pages %v", repos.asked)
+	}
+}
+
+func TestFindHighestTag(t *testing.T) {
+	tests := []struct {
+		tags           []string
+		highest        string
+		highestStable  string
+		expectNotFound bool
+	}{
+		{tags: []string{"v1.2.0", "v1.3.0-rc.1", "v1.2.1"}, highest: "v1.3.0-rc.1", highestStable: "v1.2.1"},
+		{tags: []string{"v1.3.0-rc.2", "v1.3.0", "v1.3.0-rc.10"}, highest: "v1.3.0", highestStable: "v1.3.0"},
+		{tags: []string{"1.4.0", "v1.3.0"}, highest: "1.4.0", highestStable: "1.4.0"},
+		{tags: []string{"v2.0.0", "1.9.9", "latest"}, highest: "v2.0.0", highestStable: "v2.0.0"},
+		{tags: []string{"v2.0.0-beta.1", "1.9.9"}, highest: "v2.0.0-beta.1", highestStable: "1.9.9"},
+		{tags: []string{"latest", "release-1", "v1.2"}, expectNotFound: true},
+		{tags: nil, expectNotFound: true},
+	}
+	log := logrus.StandardLogger()
+	for _, test := range tests {
+		repos := newMockRepositories()
+		repos.tags = test.tags
+		c := &Client{log: log, repos: repos}
+
+		tag, sha, err := c.FindHighestTag(context.Background(), "softleader", "s2i")
+		if test.expectNotFound {
+			if KindOf(err) != KindNotFound {
+				t.Errorf("%v has no semver tag and should be not found, but got %q (%v)", test.tags, tag, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag != test.highest || sha != "sha-of-"+test.highest {
+			t.Errorf("highest tag of %v should be %s, but got %s (%s)", test.tags, test.highest, tag, sha)
+		}
+		stable, err := findHighestTag(context.Background(), log, repos, "softleader", "s2i", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stable != test.highestStable {
+			t.Errorf("highest stable tag of %v should be %s, but got %s", test.tags, test.highestStable, stable)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"fmt"
 	"github.com/blang/semver"
+	"path"
 	"regexp"
 	"strings"
 )
@@ -47,6 +48,31 @@ func (m *RegexMatcher) Matches(s string) bool {
 	return false
 }
 
+// NewGlobMatcher 建立 GlobMatcher 物件, pattern 的語法同 path.Match, 如: release/*
+func NewGlobMatcher(patterns []string) (*GlobMatcher, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("requires a valid glob pattern: %s", err)
+		}
+	}
+	return &GlobMatcher{patterns: patterns}, nil
+}
+
+// GlobMatcher glob 判斷, * 不會匹配 /, 因此 release/* 不會匹配 release/1.0/hotfix
+type GlobMatcher struct {
+	patterns []string
+}
+
+// Matches 判斷傳入 tag 是否匹配
+func (m *GlobMatcher) Matches(s string) bool {
+	for _, pattern := range m.patterns {
+		if matched, _ := path.Match(pattern, s); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // NewSemVerMatcher 建立 SemVerMatcher 物件
 func NewSemVerMatcher(ranges []string) (*SemVerMatcher, error) {
 	m := &SemVerMatcher{}
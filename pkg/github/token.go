@@ -0,0 +1,107 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultHost = "github.com"
+	envToken    = "GITHUB_TOKEN"
+)
+
+var (
+	// ErrTokenNotFound 代表無法找到任何可以跟 github 互動的 token
+	ErrTokenNotFound = errors.New("github access token is required, please pass it explicitly, export $GITHUB_TOKEN or add the github.com entry to ~/.netrc")
+)
+
+// ResolveToken 依照以下先後順序找出跟 github 互動的 token:
+//
+//  1. 傳入的 token
+//  2. ClientOptions.TokenFile 檔案的內容, 如: 以檔案掛載的 Kubernetes secret
+//  3. $GITHUB_TOKEN 環境變數
+//  4. ~/.netrc 中 github.com (或 GitHub Enterprise Server host) 的 password
+//
+// 都找不到時回傳 ErrTokenNotFound, TokenFile 無法讀取時回傳錯誤
+func ResolveToken(token string) (string, error) {
+	if token = strings.TrimSpace(token); token != "" {
+		return token, nil
+	}
+	if p := clientOptions.TokenFile; p != "" {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %s", err)
+		}
+		if token = strings.TrimSpace(string(b)); token != "" {
+			return token, nil
+		}
+	}
+	if token = strings.TrimSpace(os.Getenv(envToken)); token != "" {
+		return token, nil
+	}
+	if token = netrcToken(host()); token != "" {
+		return token, nil
+	}
+	return "", ErrTokenNotFound
+}
+
+// host 回傳目前互動的 github host
+func host() string {
+	if clientOptions.BaseURL == "" {
+		return defaultHost
+	}
+	u, err := url.Parse(clientOptions.BaseURL)
+	if err != nil || u.Hostname() == "" {
+		return defaultHost
+	}
+	return u.Hostname()
+}
+
+func netrcToken(machine string) string {
+	home, err := homedir.Dir()
+	if err != nil {
+		return ""
+	}
+	b, err := ioutil.ReadFile(filepath.Join(home, ".netrc"))
+	if err != nil {
+		return ""
+	}
+	return findNetrcPassword(string(b), machine)
+}
+
+// findNetrcPassword 從 .netrc 內容中找出 machine 的 password, 若沒有則使用 default 的 password
+func findNetrcPassword(netrc, machine string) string {
+	var current, password, fallback string
+	fields := strings.Fields(netrc)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				current = fields[i]
+			}
+		case "default":
+			current = "default"
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				if current == machine && password == "" {
+					password = fields[i]
+				}
+				if current == "default" && fallback == "" {
+					fallback = fields[i]
+				}
+			}
+		}
+	}
+	if password != "" {
+		return password
+	}
+	return fallback
+}
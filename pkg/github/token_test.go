@@ -0,0 +1,50 @@
+package github
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindNetrcPassword(t *testing.T) {
+	netrc := `machine gitlab.com
+	login me
+	password gitlab-token
+machine github.com login me password github-token
+default login anonymous password default-token`
+
+	if p := findNetrcPassword(netrc, "github.com"); p != "github-token" {
+		t.Errorf("password of github.com should be github-token, but got %q", p)
+	}
+	if p := findNetrcPassword(netrc, "github.example.com"); p != "default-token" {
+		t.Errorf("password of unknown machine should fallback to default-token, but got %q", p)
+	}
+	if p := findNetrcPassword("machine gitlab.com login me password gitlab-token", "github.com"); p != "" {
+		t.Errorf("password should be empty, but got %q", p)
+	}
+}
+
+func TestResolveTokenFromFile(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	defer SetClientOptions(nil)
+
+	p := filepath.Join(tmp, "token")
+	ioutil.WriteFile(p, []byte("  file-token\n"), 0600)
+	SetClientOptions(&ClientOptions{TokenFile: p})
+	if token, err := ResolveToken(""); err != nil || token != "file-token" {
+		t.Errorf("token should be read from file, but got %q (err: %v)", token, err)
+	}
+	if token, _ := ResolveToken("explicit-token"); token != "explicit-token" {
+		t.Errorf("explicit token should take precedence over token file, but got %q", token)
+	}
+
+	SetClientOptions(&ClientOptions{TokenFile: filepath.Join(tmp, "missing")})
+	if _, err := ResolveToken(""); err == nil {
+		t.Error("unreadable token file should return an error")
+	}
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	redacted = "***"
+	// defaultAPIVersion 預設固定使用的 GitHub REST API 版本
+	defaultAPIVersion = "2022-11-28"
+	apiVersionHeader  = "X-GitHub-Api-Version"
+)
+
+// apiVersionTransport 在每個呼叫 GitHub API 的 request 加上 X-GitHub-Api-Version header, 讓 GitHub 調整 API 時行為保持一致
+// request 已帶有該 header 時不會覆蓋
+type apiVersionTransport struct {
+	base    http.RoundTripper
+	version string
+}
+
+func (t *apiVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(apiVersionHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTripper 不應修改傳入的 request, 因此複製一份再加上 header
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set(apiVersionHeader, t.version)
+	return t.base.RoundTrip(r)
+}
+
+// loggingTransport 以 debug level 記錄每個呼叫 GitHub API 的 method, path, status code 及花費時間
+// 安裝在 oauth2 及 retry 之下, 因此每次重試都會各記錄一筆, Authorization header 只會留下認證方式
+type loggingTransport struct {
+	base http.RoundTripper
+	log  logrus.FieldLogger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := now().Sub(start)
+	if err != nil {
+		t.log.Debugf("GitHub API %s %s failed in %s (authorization: %s): %s", req.Method, redactURL(req.URL), elapsed, redactAuthorization(req.Header.Get("Authorization")), err)
+		return resp, err
+	}
+	t.log.Debugf("GitHub API %s %s %d in %s (authorization: %s)", req.Method, redactURL(req.URL), resp.StatusCode, elapsed, redactAuthorization(req.Header.Get("Authorization")))
+	return resp, nil
+}
+
+// redactAuthorization 隱藏 Authorization header 的 credentials, 只保留認證方式, 如: Bearer ***
+func redactAuthorization(auth string) string {
+	if auth == "" {
+		return "none"
+	}
+	if i := strings.Index(auth, " "); i > 0 {
+		return auth[:i] + " " + redacted
+	}
+	return redacted
+}
+
+// redactURL 回傳 path 及 query, 並隱藏 query 中可能帶有的 credentials
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for _, key := range []string{"access_token", "client_secret"} {
+		if q.Get(key) != "" {
+			q.Set(key, redacted)
+		}
+	}
+	if len(q) == 0 {
+		return u.Path
+	}
+	return u.Path + "?" + q.Encode()
+}
+
+// newTransport 依照 client 選項建立跟 github 互動的底層 transport, 設定值同 http.DefaultTransport
+// 沒有指定 proxy 時, 會依照 $HTTP_PROXY, $HTTPS_PROXY 及 $NO_PROXY 環境變數決定
+// 預設會驗證 TLS 憑證, 只有明確指定 InsecureSkipVerify 時才不驗證
+// opts.Timeout 以 ResponseHeaderTimeout 套用在每次 request, 從 request (含 body) 送完後開始計算, 因此不會中斷上傳及下載的傳輸
+func newTransport(opts *ClientOptions) (*http.Transport, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("requires a valid proxy url: %s", err)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+	if opts.InsecureSkipVerify {
+		// 只在需要時才指定 TLSClientConfig, 否則會關閉預設的 HTTP/2 支援
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return transport, nil
+}
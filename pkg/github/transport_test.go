@@ -0,0 +1,222 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport, err := newTransport(&ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("TLS certificate should be verified by default")
+	}
+	transport, err = newTransport(&ClientOptions{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("TLS certificate verification should be skipped")
+	}
+	if _, err := newTransport(&ClientOptions{Proxy: "://proxy"}); err == nil {
+		t.Error("invalid proxy url should return an error")
+	}
+}
+
+func TestNewClientUserAgent(t *testing.T) {
+	defer SetClientOptions(nil)
+	log := logrus.StandardLogger()
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+
+	client, err := newClient(context.Background(), log, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.UserAgent != defaultUserAgent {
+		t.Errorf("user agent should be %q, but got %q", defaultUserAgent, client.UserAgent)
+	}
+	SetClientOptions(&ClientOptions{UserAgent: "s2i/v1.0.0", BaseURL: "https://github.example.com/api/v3/"})
+	if client, err = newClient(context.Background(), log, ts); err != nil {
+		t.Fatal(err)
+	}
+	if client.UserAgent != "s2i/v1.0.0" {
+		t.Errorf("user agent should be s2i/v1.0.0, but got %q", client.UserAgent)
+	}
+}
+
+func TestLoggingTransport(t *testing.T) {
+	log := logrus.New()
+	log.SetLevel(logrus.DebugLevel)
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+
+	lt := &loggingTransport{
+		log: log,
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return okResponse(req), nil
+		}),
+	}
+	req, _ := http.NewRequest("GET", "https://api.github.com/repos/softleader/s2i/releases?access_token=secret&page=2", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	if _, err := lt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if strings.Contains(out, "secret") {
+		t.Errorf("credentials should be redacted, but got %q", out)
+	}
+	for _, expected := range []string{"GET", "/repos/softleader/s2i/releases", "page=2", "200", "Bearer ***"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("log should contain %q, but got %q", expected, out)
+		}
+	}
+}
+
+func TestNewClientUploadURL(t *testing.T) {
+	defer SetClientOptions(nil)
+	log := logrus.StandardLogger()
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+
+	SetClientOptions(&ClientOptions{BaseURL: "https://github.example.com/api/v3/", UploadURL: "https://uploads.github.example.com/api/uploads/"})
+	client, err := newClient(context.Background(), log, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := client.UploadURL.String(); u != "https://uploads.github.example.com/api/uploads/" {
+		t.Errorf("upload url should be on the uploads host, but got %q", u)
+	}
+
+	SetClientOptions(&ClientOptions{BaseURL: "https://github.example.com/api/v3/"})
+	if client, err = newClient(context.Background(), log, ts); err != nil {
+		t.Fatal(err)
+	}
+	if u := client.UploadURL.String(); u != "https://github.example.com/api/uploads/" {
+		t.Errorf("upload url should be derived from base url, but got %q", u)
+	}
+
+	SetClientOptions(&ClientOptions{UploadURL: "https://uploads.proxy.example.com"})
+	if client, err = newClient(context.Background(), log, ts); err != nil {
+		t.Fatal(err)
+	}
+	if u := client.UploadURL.String(); u != "https://uploads.proxy.example.com/" {
+		t.Errorf("upload url should be overridden without base url, but got %q", u)
+	}
+	if u := client.BaseURL.String(); u != "https://api.github.com/" {
+		t.Errorf("base url should stay github.com, but got %q", u)
+	}
+}
+
+func TestEnterpriseUploadURL(t *testing.T) {
+	for base, expected := range map[string]string{
+		"https://github.example.com/api/v3/": "https://github.example.com/api/uploads/",
+		"https://github.example.com/api/v3":  "https://github.example.com/api/uploads/",
+		"https://github.example.com/":        "https://github.example.com/api/uploads/",
+		"http://127.0.0.1:8080/ghe/api/v3/":  "http://127.0.0.1:8080/ghe/api/uploads/",
+	} {
+		u, err := enterpriseUploadURL(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u != expected {
+			t.Errorf("upload url of %s should be %s, but got %s", base, expected, u)
+		}
+	}
+}
+
+func TestNewClientWithHTTPClient(t *testing.T) {
+	defer SetClientOptions(nil)
+	SetClientOptions(&ClientOptions{BaseURL: "https://github.example.com/api/v3/", UserAgent: "s2i/test"})
+	var got *http.Request
+	hc := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		resp := okResponse(req)
+		resp.Body = ioutil.NopCloser(strings.NewReader(`{"default_branch":"main"}`))
+		return resp, nil
+	})}
+	c, err := NewClientWithHTTPClient(logrus.StandardLogger(), hc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := c.GetDefaultBranch(context.Background(), "softleader", "s2i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "main" {
+		t.Errorf("default branch should be main, but got %q", branch)
+	}
+	if got == nil || got.URL.String() != "https://github.example.com/api/v3/repos/softleader/s2i" {
+		t.Errorf("request should be sent through the injected http client to base url, but got %v", got)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != "s2i/test" {
+		t.Errorf("user agent should be s2i/test, but got %q", ua)
+	}
+	if _, err := NewClientWithHTTPClient(logrus.StandardLogger(), nil); KindOf(err) != KindInvalid {
+		t.Errorf("nil http client should be KindInvalid, but got %v", err)
+	}
+}
+
+func TestNewClientAPIVersion(t *testing.T) {
+	var versions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions = append(versions, r.Header.Get(apiVersionHeader))
+		w.Write([]byte(`{"default_branch":"main"}`))
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	log := logrus.StandardLogger()
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})
+
+	for _, version := range []string{"", "2099-01-01"} {
+		SetClientOptions(&ClientOptions{BaseURL: server.URL + "/", APIVersion: version})
+		client, err := newClient(context.Background(), log, ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := client.Repositories.Get(context.Background(), "softleader", "s2i"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(versions) != 2 || versions[0] != defaultAPIVersion || versions[1] != "2099-01-01" {
+		t.Errorf("api version header should be [%s 2099-01-01], but got %v", defaultAPIVersion, versions)
+	}
+}
+
+func TestClientKeepsOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/softleader/s2i/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "5")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1,"tag_name":"v1.2.3"}`))
+	}))
+	defer server.Close()
+	defer SetClientOptions(nil)
+	log := logrus.New()
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+
+	SetClientOptions(&ClientOptions{BaseURL: server.URL + "/", RateLimitWarning: 100})
+	c, err := NewClientWithHTTPClient(log, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetClientOptions(nil) // 不應影響已建立的 Client
+	if _, err := c.CreateRelease(context.Background(), "softleader", "s2i", "master", "v1.2.3", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "5/5000") {
+		t.Errorf("should warn with the rate limit threshold when the client is created, but got %q", b.String())
+	}
+}
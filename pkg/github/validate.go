@@ -0,0 +1,31 @@
+package github
+
+import (
+	"errors"
+	"strings"
+)
+
+// validateRepo 檢查 owner 及 repo 皆有傳入
+func validateRepo(owner, repo string) error {
+	if strings.TrimSpace(owner) == "" {
+		return invalid(errors.New("owner is required"))
+	}
+	if strings.TrimSpace(repo) == "" {
+		return invalid(errors.New("repo is required"))
+	}
+	return nil
+}
+
+// validateRelease 檢查建立 release 所需的 owner, repo, branch 及 tag 皆有傳入
+func validateRelease(owner, repo, branch, tag string) error {
+	if err := validateRepo(owner, repo); err != nil {
+		return err
+	}
+	if strings.TrimSpace(branch) == "" {
+		return invalid(errors.New("branch is required"))
+	}
+	if strings.TrimSpace(tag) == "" {
+		return invalid(errors.New("tag is required"))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestValidateRelease(t *testing.T) {
+	tests := []struct {
+		owner, repo, branch, tag string
+		expected                 string
+	}{
+		{"", "s2i", "master", "v1.0.0", "owner is required"},
+		{"softleader", " ", "master", "v1.0.0", "repo is required"},
+		{"softleader", "s2i", "", "v1.0.0", "branch is required"},
+		{"softleader", "s2i", "master", "", "tag is required"},
+	}
+	for _, test := range tests {
+		err := validateRelease(test.owner, test.repo, test.branch, test.tag)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("expected error %q, but got %v", test.expected, err)
+		}
+	}
+	if err := validateRelease("softleader", "s2i", "master", "v1.0.0"); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+}
+
+func TestClientValidatesRepo(t *testing.T) {
+	// 任何 GitHub API 的呼叫都會讓測試失敗, owner 或 repo 為空時應在呼叫前就回傳 KindInvalid
+	c := &Client{log: logrus.StandardLogger(), repos: &noCallRepositories{t: t}, git: &noCallGit{t: t}}
+	ctx := context.Background()
+	tests := map[string]func(owner, repo string) error{
+		"ListReleases": func(owner, repo string) error {
+			_, err := c.ListReleases(ctx, owner, repo)
+			return err
+		},
+		"PublishRelease": func(owner, repo string) error {
+			_, err := c.PublishRelease(ctx, owner, repo, "v1.2.3")
+			return err
+		},
+		"UpdateRelease": func(owner, repo string) error {
+			_, err := c.UpdateRelease(ctx, owner, repo, "v1.2.3", "name", "body")
+			return err
+		},
+		"TagExists": func(owner, repo string) error {
+			_, err := c.TagExists(ctx, owner, repo, "v1.2.3")
+			return err
+		},
+		"DeleteReleaseOnly": func(owner, repo string) error {
+			return c.DeleteReleaseOnly(ctx, owner, repo, "v1.2.3", true)
+		},
+		"PromoteRelease": func(owner, repo string) error {
+			_, err := c.PromoteRelease(ctx, owner, repo, "v1.2.3")
+			return err
+		},
+		"AppendToReleaseBody": func(owner, repo string) error {
+			_, err := c.AppendToReleaseBody(ctx, owner, repo, "v1.2.3", "content")
+			return err
+		},
+	}
+	for name, call := range tests {
+		if err := call("", "s2i"); KindOf(err) != KindInvalid {
+			t.Errorf("%s with empty owner should be invalid, but got %v", name, err)
+		}
+		if err := call("softleader", ""); KindOf(err) != KindInvalid {
+			t.Errorf("%s with empty repo should be invalid, but got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package github
+
+import (
+	"fmt"
+	"github.com/blang/semver"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Bump 代表下一版要增加的版號層級
+type Bump string
+
+const (
+	// BumpPatch 增加 patch 版號, 如: 1.2.3 -> 1.2.4
+	BumpPatch Bump = "patch"
+	// BumpMinor 增加 minor 版號並歸零 patch, 如: 1.2.3 -> 1.3.0
+	BumpMinor Bump = "minor"
+	// BumpMajor 增加 major 版號並歸零 minor 及 patch, 如: 1.2.3 -> 2.0.0
+	BumpMajor Bump = "major"
+	// BumpAuto 依照 Conventional Commits 自動判斷要增加的版號層級
+	BumpAuto Bump = "auto"
+	// BumpPrerelease 增加 pre-release 中最後一個數字, 如: 1.2.0-rc.1 -> 1.2.0-rc.2
+	BumpPrerelease Bump = "prerelease"
+	// BumpFinalize 移除 pre-release 使其成為正式版, 如: 1.2.0-rc.1 -> 1.2.0
+	BumpFinalize Bump = "finalize"
+)
+
+var (
+	// 如: "feat: ...", "fix(parser): ...", "refactor!: ..."
+	conventional = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?: `)
+	breaking     = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+)
+
+// NextVersion 將 base 依照 b 層級增加版號後回傳, base 有 "v" 開頭時下一版也會加上 "v", 如: v1.2.3 及 minor -> v1.3.0
+// 不需要連線到 GitHub, 適合 base 來自檔案 (如: VERSION) 或參數時使用; BumpAuto 需要 commits 判斷, 因此不支援
+func NextVersion(base string, b Bump) (string, error) {
+	if b == BumpAuto {
+		return "", fmt.Errorf("bump level %q requires commits to infer, use FindNextReleaseVersion instead", b)
+	}
+	sv, err := semver.Parse(strings.TrimPrefix(base, "v"))
+	if err != nil {
+		return "", fmt.Errorf("invalid version %q: %s", base, err)
+	}
+	if err := bump(&sv, b); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(base, "v") {
+		return "v" + sv.String(), nil
+	}
+	return sv.String(), nil
+}
+
+// EnvironmentTag 以 base 版號及環境名稱組成該環境的 pre-release tag, 如: v1.2.0 及 staging -> v1.2.0-staging
+// base 必須為正式版, env 必須是合法的 semver pre-release 識別字 (英數字及 "-"), 會保留 base 的 "v" 開頭
+func EnvironmentTag(base, env string) (string, error) {
+	sv, err := semver.Parse(strings.TrimPrefix(base, "v"))
+	if err != nil {
+		return "", fmt.Errorf("invalid version %q: %s", base, err)
+	}
+	if len(sv.Pre) > 0 {
+		return "", fmt.Errorf("version %q is already a pre-release", base)
+	}
+	pre, err := semver.NewPRVersion(env)
+	if err != nil {
+		return "", fmt.Errorf("invalid environment %q: %s", env, err)
+	}
+	if pre.IsNum {
+		return "", fmt.Errorf("environment %q must not be numeric", env)
+	}
+	sv.Pre = []semver.PRVersion{pre}
+	sv.Build = nil
+	if strings.HasPrefix(base, "v") {
+		return "v" + sv.String(), nil
+	}
+	return sv.String(), nil
+}
+
+// bump 依照傳入的層級增加版號, 沒指定層級時視為 BumpPatch
+func bump(sv *semver.Version, b Bump) error {
+	switch b {
+	case "", BumpPatch:
+		sv.Patch++
+	case BumpMinor:
+		sv.Minor++
+		sv.Patch = 0
+	case BumpMajor:
+		sv.Major++
+		sv.Minor = 0
+		sv.Patch = 0
+	case BumpPrerelease:
+		if len(sv.Pre) == 0 {
+			return fmt.Errorf("%s has no pre-release version to bump", sv)
+		}
+		sv.Pre = bumpPrerelease(sv.Pre)
+		sv.Build = nil
+		return nil
+	case BumpFinalize:
+		if len(sv.Pre) == 0 {
+			return fmt.Errorf("%s is already a final version", sv)
+		}
+	default:
+		return fmt.Errorf("unsupported bump level: %q", b)
+	}
+	sv.Pre = nil
+	sv.Build = nil
+	return nil
+}
+
+// bumpPrerelease 增加最後一個數字的 pre-release 版號, 若沒有數字則在最後加上 1, 如: rc -> rc.1
+func bumpPrerelease(pre []semver.PRVersion) []semver.PRVersion {
+	bumped := make([]semver.PRVersion, len(pre))
+	copy(bumped, pre)
+	for i := len(bumped) - 1; i >= 0; i-- {
+		if bumped[i].IsNum {
+			bumped[i].VersionNum++
+			return bumped
+		}
+	}
+	return append(bumped, semver.PRVersion{VersionNum: 1, IsNum: true})
+}
+
+// IsNewer 判斷版號 a 是否大於 b, 兩者皆可以有 "v" 前綴, 如: v1.10.0 大於 1.9.0
+// 任一版號不是合法的 semver 時回傳錯誤
+func IsNewer(a, b string) (bool, error) {
+	va, err := semver.Parse(strings.TrimPrefix(a, "v"))
+	if err != nil {
+		return false, fmt.Errorf("invalid version %q: %s", a, err)
+	}
+	vb, err := semver.Parse(strings.TrimPrefix(b, "v"))
+	if err != nil {
+		return false, fmt.Errorf("invalid version %q: %s", b, err)
+	}
+	return va.GT(vb), nil
+}
+
+// PrereleaseVersion 在 base 版號後加上 pre-release 識別字, 如: v1.2.0 及 rc.1 -> v1.2.0-rc.1
+// base 必須為正式版本, suffix 必須為合法的 semver pre-release, 否則回傳錯誤
+func PrereleaseVersion(base, suffix string) (string, error) {
+	sv, err := semver.Parse(strings.TrimPrefix(base, "v"))
+	if err != nil {
+		return "", err
+	}
+	if len(sv.Pre) > 0 {
+		return "", fmt.Errorf("%s is already a pre-release version", base)
+	}
+	if suffix == "" {
+		return "", fmt.Errorf("pre-release suffix is required")
+	}
+	for _, id := range strings.Split(suffix, ".") {
+		pre, err := semver.NewPRVersion(id)
+		if err != nil {
+			return "", fmt.Errorf("invalid pre-release suffix %q: %s", suffix, err)
+		}
+		sv.Pre = append(sv.Pre, pre)
+	}
+	v := sv.String()
+	if strings.HasPrefix(base, "v") {
+		v = "v" + v
+	}
+	return v, nil
+}
+
+// RCSuffix 回傳第 n 個 release candidate 的 pre-release 識別字, 如: rc.1
+func RCSuffix(n int) string {
+	return fmt.Sprintf("rc.%d", n)
+}
+
+// TimestampSuffix 回傳以 UTC 時間組成的 pre-release 識別字, 如: 20191001123000
+func TimestampSuffix(t time.Time) string {
+	return t.UTC().Format("20060102150405")
+}
+
+// NowSuffix 回傳以目前 UTC 時間組成的 pre-release 識別字, 同 TimestampSuffix
+func NowSuffix() string {
+	return TimestampSuffix(now())
+}
+
+// SHASuffix 回傳以 short commit sha 組成的 pre-release 識別字, 如: g1a2b3c4
+// 跟 git describe 一樣加上 "g" 前綴, 避免 sha 全為數字且以 0 開頭時不符合 semver 規範
+func SHASuffix(sha string) string {
+	if len(sha) > shortSHA {
+		sha = sha[:shortSHA]
+	}
+	return "g" + sha
+}
+
+// inferBump 依照 Conventional Commits (https://www.conventionalcommits.org) 判斷 commit messages 要增加的版號層級
+// 任一 commit 有 breaking change 即為 BumpMajor, 有 feat 為 BumpMinor, 其餘皆為 BumpPatch
+func inferBump(messages []string) Bump {
+	b := BumpPatch
+	for _, message := range messages {
+		if breaking.MatchString(message) {
+			return BumpMajor
+		}
+		subject := strings.SplitN(message, "\n", 2)[0]
+		groups := conventional.FindStringSubmatch(subject)
+		if len(groups) < 1 {
+			continue
+		}
+		if groups[3] == "!" {
+			return BumpMajor
+		}
+		if groups[1] == "feat" {
+			b = BumpMinor
+		}
+	}
+	return b
+}
@@ -0,0 +1,203 @@
+package github
+
+import (
+	"github.com/blang/semver"
+	"testing"
+	"time"
+)
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		version  string
+		bump     Bump
+		expected string
+	}{
+		{"1.2.3", "", "1.2.4"},
+		{"1.2.3", BumpPatch, "1.2.4"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"1.2.3", BumpMajor, "2.0.0"},
+		{"1.2.3-rc.1+build", BumpPatch, "1.2.4"},
+		{"1.2.0-rc.1", BumpPrerelease, "1.2.0-rc.2"},
+		{"1.2.0-rc.9.beta", BumpPrerelease, "1.2.0-rc.10.beta"},
+		{"1.2.0-rc", BumpPrerelease, "1.2.0-rc.1"},
+		{"1.2.0-rc.1+build", BumpFinalize, "1.2.0"},
+	}
+	for _, test := range tests {
+		sv := semver.MustParse(test.version)
+		if err := bump(&sv, test.bump); err != nil {
+			t.Fatal(err)
+		}
+		if v := sv.String(); v != test.expected {
+			t.Errorf("bump %q of %s should be %s, but got %s", test.bump, test.version, test.expected, v)
+		}
+	}
+	for _, b := range []Bump{"unknown", BumpPrerelease, BumpFinalize} {
+		sv := semver.MustParse("1.2.3")
+		if err := bump(&sv, b); err == nil {
+			t.Errorf("bump %q of 1.2.3 should return an error", b)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		base     string
+		bump     Bump
+		expected string
+	}{
+		{"v1.2.3", "", "v1.2.4"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"v1.2.3", BumpMajor, "v2.0.0"},
+		{"v1.2.0-rc.1", BumpPrerelease, "v1.2.0-rc.2"},
+		{"1.2.0-rc.1", BumpFinalize, "1.2.0"},
+	}
+	for _, test := range tests {
+		next, err := NextVersion(test.base, test.bump)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != test.expected {
+			t.Errorf("next %s version of %s should be %s, but got %s", test.bump, test.base, test.expected, next)
+		}
+	}
+	for _, b := range []Bump{BumpAuto, "hotfix"} {
+		if _, err := NextVersion("v1.2.3", b); err == nil {
+			t.Errorf("bump %q should return an error", b)
+		}
+	}
+	if _, err := NextVersion("latest", BumpPatch); err == nil {
+		t.Error("non-semver base should return an error")
+	}
+}
+
+func TestNextVersionOptions_initialVersion(t *testing.T) {
+	if v := (&NextVersionOptions{}).initialVersion(); v != "0.1.0" {
+		t.Errorf("default initial version should be 0.1.0, but got %q", v)
+	}
+	if v := (&NextVersionOptions{VPrefix: true}).initialVersion(); v != "v0.1.0" {
+		t.Errorf("initial version with v prefix should be v0.1.0, but got %q", v)
+	}
+	if v := (&NextVersionOptions{InitialVersion: "v1.0.0"}).initialVersion(); v != "1.0.0" {
+		t.Errorf("initial version without v prefix should be 1.0.0, but got %q", v)
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"v1.10.0", "1.9.0", true},
+		{"1.2.3", "v1.2.3", false},
+		{"v1.2.3-rc.1", "v1.2.3", false},
+		{"v1.2.3", "v1.2.3-rc.1", true},
+	}
+	for _, test := range tests {
+		newer, err := IsNewer(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newer != test.expected {
+			t.Errorf("%s should be newer than %s: %v, but got %v", test.a, test.b, test.expected, newer)
+		}
+	}
+	if _, err := IsNewer("latest", "v1.2.3"); err == nil {
+		t.Error("non-semver version should return an error")
+	}
+}
+
+func TestPrereleaseVersion(t *testing.T) {
+	tests := []struct {
+		base     string
+		suffix   string
+		expected string
+	}{
+		{"v1.2.0", RCSuffix(1), "v1.2.0-rc.1"},
+		{"1.2.0", TimestampSuffix(time.Date(2019, 10, 1, 12, 30, 0, 0, time.UTC)), "1.2.0-20191001123000"},
+		{"v1.2.0", SHASuffix("0123456789abcdef"), "v1.2.0-g0123456"},
+	}
+	for _, test := range tests {
+		v, err := PrereleaseVersion(test.base, test.suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != test.expected {
+			t.Errorf("pre-release of %s with %q should be %s, but got %s", test.base, test.suffix, test.expected, v)
+		}
+	}
+	for _, in := range [][2]string{{"v1.2.0-rc.1", "rc.2"}, {"v1.2.0", ""}, {"v1.2.0", "rc.01"}, {"v1.2.0", "rc_1"}} {
+		if _, err := PrereleaseVersion(in[0], in[1]); err == nil {
+			t.Errorf("pre-release of %s with %q should return an error", in[0], in[1])
+		}
+	}
+}
+
+func TestNowSuffix(t *testing.T) {
+	defer func() { now = time.Now }()
+	tests := []struct {
+		now      time.Time
+		expected string
+	}{
+		{time.Date(2019, 10, 1, 12, 30, 0, 0, time.UTC), "20191001123000"},
+		{time.Date(2019, 10, 1, 20, 30, 0, 0, time.FixedZone("CST", 8*60*60)), "20191001123000"},
+		{time.Date(2020, 1, 1, 7, 59, 59, 999, time.FixedZone("CST", 8*60*60)), "20191231235959"},
+		{time.Date(2020, 2, 29, 0, 0, 1, 0, time.UTC), "20200229000001"},
+	}
+	for _, test := range tests {
+		fixed := test.now
+		now = func() time.Time { return fixed }
+		if suffix := NowSuffix(); suffix != test.expected {
+			t.Errorf("suffix of %s should be %s, but got %q", fixed, test.expected, suffix)
+		}
+		if suffix := TimestampSuffix(fixed); suffix != test.expected {
+			t.Errorf("timestamp suffix of %s should be %s, but got %q", fixed, test.expected, suffix)
+		}
+		if v, err := PrereleaseVersion("v1.2.0", NowSuffix()); err != nil || v != "v1.2.0-"+test.expected {
+			t.Errorf("pre-release of %s should be v1.2.0-%s, but got %s (%v)", fixed, test.expected, v, err)
+		}
+	}
+}
+
+func TestInferBump(t *testing.T) {
+	tests := []struct {
+		messages []string
+		expected Bump
+	}{
+		{nil, BumpPatch},
+		{[]string{"update readme"}, BumpPatch},
+		{[]string{"fix: typo", "chore(deps): bump"}, BumpPatch},
+		{[]string{"fix: typo", "feat(cmd): add bump flag"}, BumpMinor},
+		{[]string{"feat: add bump flag", "refactor!: drop go 1.11"}, BumpMajor},
+		{[]string{"feat: add bump flag\n\nBREAKING CHANGE: signature changed"}, BumpMajor},
+		{[]string{"docs: mention feat: in body"}, BumpPatch},
+	}
+	for _, test := range tests {
+		if b := inferBump(test.messages); b != test.expected {
+			t.Errorf("messages %q should infer %s, but got %s", test.messages, test.expected, b)
+		}
+	}
+}
+
+func TestEnvironmentTag(t *testing.T) {
+	tests := []struct {
+		base, env, expected string
+	}{
+		{"v1.2.0", "staging", "v1.2.0-staging"},
+		{"1.2.0", "prod", "1.2.0-prod"},
+		{"v1.2.0", "us-east", "v1.2.0-us-east"},
+	}
+	for _, test := range tests {
+		tag, err := EnvironmentTag(test.base, test.env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag != test.expected {
+			t.Errorf("tag of %s in %s should be %s, but got %s", test.base, test.env, test.expected, tag)
+		}
+	}
+	for _, test := range [][2]string{{"v1.2.0", ""}, {"v1.2.0", "pro_d"}, {"v1.2.0", "1"}, {"v1.2.0-rc.1", "staging"}, {"latest", "staging"}} {
+		if tag, err := EnvironmentTag(test[0], test[1]); err == nil {
+			t.Errorf("tag of %s in %q should be invalid, but got %s", test[0], test[1], tag)
+		}
+	}
+}